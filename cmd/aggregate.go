@@ -0,0 +1,523 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+const (
+	filepath       = "filepath"
+	postcode       = "postcode"
+	timeRange      = "timerange"
+	names          = "names"
+	matchMode      = "match-mode"
+	threshold      = "threshold"
+	fromDate       = "from"
+	toDate         = "to"
+	aliases        = "aliases"
+	postcodeGroup  = "postcode-group"
+	regions        = "regions"
+	raw            = "raw"
+	state          = "state"
+	checkpoint     = "checkpoint"
+	interval       = "checkpoint-interval"
+	resume         = "resume"
+	perFileSummary = "per-file"
+	schemaMapping  = "schema"
+	attributes     = "attributes"
+	groupBy        = "group-by"
+	where          = "where"
+	verbose        = "verbose"
+	help           = "help"
+)
+
+var (
+	defaultPostcode  = "10120"
+	defaultTimeRange = "10AM - 3PM"
+	defaultNames     = "Potato,Veggie,Mushroom"
+	defaultMatchMode = string(internal.SubstringMatch)
+	defaultThreshold = strconv.FormatFloat(internal.DefaultFuzzyThreshold, 'f', -1, 64)
+
+	fileOption = option{name: filepath, short: "f", example: "'test/hf_test_calculation_fixtures.json'",
+		usage: "JSON file with the records (required)"}
+	filesOption = option{name: filepath, short: "f", example: "'exports/2026-10-*.json'",
+		usage: "Comma separated JSON files, directories or glob patterns with the records (required)"}
+	verboseOption = option{name: verbose, short: "v", isBool: true, usage: "Show the parsing progress and duration"}
+	perFileOption = option{name: perFileSummary, isBool: true, usage: "Add the summary of each file to the output"}
+	schemaOption  = option{name: schemaMapping, example: "'postcode=zip,recipe=recipe_name,delivery=order.delivery'",
+		usage: "Comma separated field=path pairs mapping the record fields to the JSON keys of the file"}
+	attributesOption = option{name: attributes, example: "'box_size,customer_type,country=market.country'",
+		usage: "Comma separated extra attributes kept from the records: name or name=path"}
+	// groupOptions keep extra attributes of the records and count the deliveries per group.
+	groupOptions = []option{
+		attributesOption,
+		{name: groupBy, example: "'country,box_size'",
+			usage: "Comma separated record fields or attributes whose combinations are counted"},
+	}
+	// filterOptions set internal.Filter.
+	filterOptions = []option{
+		{name: postcode, short: "p", value: defaultPostcode, example: "'10021'",
+			usage: "Postcode of the deliveries counted by time"},
+		{name: timeRange, short: "r", value: defaultTimeRange, example: "'Friday 10AM - 2PM'",
+			usage: "Time range of the deliveries counted by postcode"},
+		{name: names, short: "n", value: defaultNames, example: "'Veggie,Potato'",
+			usage: "Comma separated names matched against recipe names"},
+		{name: matchMode, short: "m", value: defaultMatchMode, example: "'fuzzy'",
+			usage: "How names match: substring, word, prefix, regex, glob or fuzzy"},
+		{name: threshold, short: "t", value: defaultThreshold, example: "'0.7'",
+			usage: "Minimum similarity (above 0, at most 1) of the fuzzy match mode"},
+		{name: fromDate, example: "'2026-10-01'", usage: "First delivery date aggregated (inclusive)"},
+		{name: toDate, example: "'2026-10-07'", usage: "Last delivery date aggregated (inclusive)"},
+		{name: where, short: "w", example: `"weekday in (Sat, Sun) AND recipe !~ 'Chicken'"`,
+			usage: "Condition the records must meet to be aggregated, on the normalized postcodes"},
+	}
+	aliasesOption = option{name: aliases, short: "a", example: "'aliases.json'",
+		usage: "JSON file mapping variant recipe names to canonical ones"}
+	regionsOption = option{name: regions, example: "'regions.json'", usage: "JSON file mapping postcodes to regions"}
+	stateOption   = option{name: state, short: "s", example: "'week.state'",
+		usage: "File with the state of the previous runs, updated with the records of the file"}
+	// checkpointOptions save checkpoints while parsing and resume from them.
+	checkpointOptions = []option{
+		{name: checkpoint, short: "c", example: "'week.checkpoint'",
+			usage: "File of the checkpoints saved while parsing"},
+		{name: interval, value: strconv.Itoa(internal.DefaultCheckpointInterval), example: "'1000000'",
+			usage: "Records parsed between checkpoints"},
+		{name: resume, isBool: true, usage: "Go on from the last checkpoint"},
+	}
+	// normalizerOptions set the normalizers of the records.
+	normalizerOptions = []option{
+		aliasesOption,
+		{name: postcodeGroup, short: "g", example: "'prefix:3'", usage: "Roll postcodes up by their first N characters"},
+		regionsOption,
+		{name: raw, isBool: true, usage: "Count names and postcodes as they are in the file"},
+	}
+
+	aggregateCommand = command{
+		name:    "aggregate",
+		summary: "Aggregate the records of JSON files",
+		description: `
+Aggregates the records of a JSON file, an array of records or NDJSON, and prints the aggregation as JSON. E.g.:
+	./recipe-aggregator aggregate -f 'test/hf_test_calculation_fixtures.json' -r 'Friday 10AM - 2PM' -p '10021' -n 'Veggie,Potato'
+
+Only the filepath is required. The filter (postcode, timerange and names) is optional.
+
+Filepath might also be a comma separated list of files, directories, whose .json, .jsonl and .ndjson files are
+read, and glob patterns. Files given after the flags are added to it, so a pattern expanded by the shell works too.
+All files are aggregated together and --per-file adds the parsed and ignored counts and the busiest postcode of each
+file to the output. E.g.:
+	./recipe-aggregator aggregate -f 'exports/2026-10-*.json' --per-file
+	./recipe-aggregator aggregate -f exports/2026-10-01.json exports/2026-10-02.json
+
+Match mode selects how names are compared against recipe names: substring, word, prefix, regex, glob or fuzzy.
+Threshold is the minimum similarity accepted by the fuzzy match mode, greater than 0 and at most 1.
+
+Recipe names are normalized before the aggregation: they are trimmed, their whitespaces are collapsed and names
+that differ only by case or Unicode composition are counted as the same recipe. Aliases is an optional JSON file
+mapping variant names to canonical ones. E.g. {"Tex Mex Tilapia": "Tex-Mex Tilapia"}.
+
+Postcodes are normalized as well: whitespaces and leading zeros are removed, so " 10120" and "010120" are "10120".
+Postcode group rolls postcodes up by their first N characters and Regions is an optional JSON file mapping
+postcodes to regions. E.g. {"10120": "Center"}. When any of them is set, the postcode filter and the busiest
+postcode are computed per group and the delivery count of every group is added to the output.
+
+Use --raw to count the names and postcodes as they are in the input JSON file.
+
+Schema maps the record fields (postcode, recipe, delivery and delivery_date) to other JSON keys, so exports with
+another schema are read as they are. A path is a dot-separated list of keys and the fields that are not mapped keep
+their names. Numbers, such as a numeric zip, are read as they are written. E.g.:
+	./recipe-aggregator aggregate -f 'orders.json' --schema 'postcode=zip,recipe=recipe_name,delivery=order.delivery'
+It might be set in the config file as well:
+	schema: [postcode=zip, recipe=recipe_name, delivery=order.delivery]
+
+Attributes keeps extra values of the records, which are dropped otherwise. Each one is a name, which is also its
+JSON key, or a name=path pair. Group by counts the deliveries of each combination of the values of record fields
+(postcode, recipe, delivery and delivery_date) and attributes, added to the output as count_per_group. E.g.:
+	./recipe-aggregator aggregate -f 'orders.json' --attributes 'box_size,country' --group-by 'country,box_size'
+
+Where is a condition the records must meet to be aggregated, applied before all the aggregations. Comparisons of a
+field and a value are combined by NOT, AND and OR, in this precedence, and grouped by parentheses. E.g.:
+	./recipe-aggregator aggregate -f 'week.json' -w "postcode starts with 101 AND weekday in (Sat, Sun) AND recipe !~ 'Chicken'"
+The operators are =, !=, <, <=, >, >= (unquoted numbers are compared as numbers), STARTS WITH, ENDS WITH,
+CONTAINS, IN and NOT IN, followed by a list of values, and ~ and !~, which match a regular expression. The fields
+are postcode, recipe, delivery, delivery_date, weekday, from_hour and to_hour, in 24-hour format, and the
+attributes. Values are words or quoted with single or double quotes. Weekdays might be abbreviated, e.g. Sat.
+The condition sees the postcodes once normalized, so the values compared to the postcode by =, != and IN are
+normalized as well: with -g 'prefix:3', postcode = 010120 is postcode = 101. The other operators compare the
+normalized postcodes to their values as they are written.
+
+Records might have a delivery date, "delivery_date": "2026-10-01", and the delivery count of each date is added to
+the output. From and to, both optional and inclusive, aggregate only the records delivered between them; records
+without a delivery date are skipped then. E.g.:
+	./recipe-aggregator aggregate -f 'exports/2026-10.json' --from '2026-10-01' --to '2026-10-07'
+
+State aggregates files incrementally. E.g. a new file every hour, each one aggregated with the previous ones:
+	./recipe-aggregator aggregate -f 'monday_10am.json' -s 'week.state'
+The records of the file are added to the state of the previous runs, if the state file exists, and the updated
+state is saved back. The aggregation is the same as the one of all files at once. The filter and the normalizers
+must be the same in every run; a state calculated with another filter is rejected.
+
+Checkpoint saves the position in the file and the aggregation so far every checkpoint interval records. If the run
+is interrupted, e.g. the container is killed, the same command with --resume goes on from the last checkpoint
+instead of the start of the file:
+	./recipe-aggregator aggregate -f 'week.json' -c 'week.checkpoint' --resume
+The checkpoint is removed when the run completes. A file changed since the checkpoint is not resumed. Checkpoints
+need a single file and no --per-file.
+
+SIGINT (Ctrl-C) or SIGTERM stops the parsing. The aggregation of the records read so far is printed with
+"partial": true and their record_count, a checkpoint is saved at the last record if --checkpoint is set, the state
+is left as it was and the exit status is 130.`,
+		options: concatOptions([]option{filesOption, schemaOption}, filterOptions, groupOptions, normalizerOptions,
+			[]option{stateOption}, checkpointOptions, []option{perFileOption, verboseOption}),
+		positional: filepath,
+		run:        runAggregate,
+	}
+)
+
+func runAggregate(c command, f flags) {
+	files := c.requiredFiles(f, filepath)
+	schema := c.loadSchema(f)
+	filter, recipes, postcodes := c.loadFilterAndNormalizers(f)
+	cp := c.loadCheckpointing(f)
+	perFile := c.bool(f, perFileSummary)
+	if cp.path != "" && (len(files) > 1 || perFile) {
+		c.fail(fmt.Errorf("--%s needs a single file and no --%s", checkpoint, perFileSummary))
+	}
+	isVerbose := c.bool(f, verbose)
+	start := time.Now()
+	if isVerbose {
+		fmt.Printf("Input\nFiles: %v\nSchema: %v\nFilter: %v\n", strings.Join(files, ", "), schema, filter)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	var (
+		aggregation internal.Aggregation
+		err         error
+	)
+	if f[state] != "" {
+		aggregation, err = aggregateState(ctx, f[state], files, schema, filter, recipes, postcodes, cp, perFile,
+			isVerbose)
+	} else {
+		aggregation, err = aggregateFiles(ctx, files, schema, filter, recipes, postcodes, cp, perFile, isVerbose)
+	}
+	fmt.Printf(internal.ConsoleClear)
+	fmt.Println(aggregation)
+
+	duration := time.Since(start)
+	if isVerbose {
+		fmt.Println(duration)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Interrupted after %d records: %v\n", aggregation.RecordCount, err)
+		os.Exit(exitInterrupted)
+	}
+}
+
+// checkpointing are the values of checkpointOptions. path is empty if no checkpoint is saved.
+type checkpointing struct {
+	path     string
+	interval int
+	resume   bool
+}
+
+// loadCheckpointing reads checkpointOptions. It fails if the interval is not a positive number or if --resume is set
+// without a checkpoint file.
+func (c command) loadCheckpointing(f flags) checkpointing {
+	n, err := strconv.Atoi(f[interval])
+	if err != nil || n <= 0 {
+		c.fail(fmt.Errorf("invalid checkpoint interval %v, want a positive number", f[interval]))
+	}
+	if c.bool(f, resume) && f[checkpoint] == "" {
+		c.fail(fmt.Errorf("--%s needs --%s", resume, checkpoint))
+	}
+
+	return checkpointing{f[checkpoint], n, c.bool(f, resume)}
+}
+
+// aggregateFiles parses files, whose records are decoded with schema, with a SummaryCalculator and returns their
+// aggregation, with the summary of each file if perFile is set. The normalizers might be nil. If ctx is done before
+// the end of the files, it returns the partial aggregation and ctx.Err().
+func aggregateFiles(ctx context.Context, files []string, schema internal.Schema, filter internal.Filter,
+	recipes *internal.RecipeNormalizer, postcodes *internal.PostcodeNormalizer, cp checkpointing,
+	perFile, isVerbose bool) (internal.Aggregation, error) {
+	calculator := internal.NewSummaryCalculator(filter)
+	summaries, parsed, ignored, err := parseFiles(ctx, files, schema, &calculator, recipes, postcodes, cp, perFile,
+		isVerbose)
+
+	return aggregate(calculator, summaries, parsed+ignored, err), err
+}
+
+// aggregate returns the aggregation of calculator with the summaries of the files. It is marked as partial with the
+// count of the records read if err, the interruption of the parsing, is not nil.
+func aggregate(calculator internal.SummaryCalculator, summaries []internal.FileSummary, records int,
+	err error) internal.Aggregation {
+	a := calculator.Aggregate()
+	a.Files = summaries
+	if err != nil {
+		a.Partial, a.RecordCount = true, records
+	}
+
+	return a
+}
+
+// parseFiles parses files in order with parseFile. If perFile is set, it also counts the postcodes of each file for
+// its summary. It returns the summaries, the counts of all files and, if ctx is done before the end of the files,
+// ctx.Err().
+func parseFiles(ctx context.Context, files []string, schema internal.Schema, calculator *internal.SummaryCalculator,
+	recipes *internal.RecipeNormalizer, postcodes *internal.PostcodeNormalizer, cp checkpointing,
+	perFile, isVerbose bool) (summaries []internal.FileSummary, parsed, ignored int, err error) {
+	for _, file := range files {
+		var (
+			counter internal.PostcodeCounter
+			calc    internal.Calculator = calculator
+		)
+		if perFile {
+			counter = make(internal.PostcodeCounter)
+			calc = internal.MultiCalculator{calculator, internal.NewFilteredCalculator(calculator.Filter, counter)}
+		}
+		if recipes != nil {
+			calc = internal.NewNormalizingCalculator(calc, recipes, postcodes)
+		}
+
+		p, i, err := parseFile(ctx, file, schema, calculator, calc, recipes, cp, isVerbose)
+		parsed, ignored = parsed+p, ignored+i
+		if perFile {
+			summaries = append(summaries, internal.FileSummary{File: file, Parsed: p, Ignored: i,
+				BusiestPostcode: counter.Busiest()})
+		}
+		if err != nil {
+			return summaries, parsed, ignored, err
+		}
+	}
+
+	return summaries, parsed, ignored, nil
+}
+
+// parseFile parses file, whose records are decoded with schema, with calc, which calculates the records with
+// calculator and normalizes the recipes with recipes, if not nil. With a checkpoint file, it saves checkpoints while
+// parsing and, with resume, restores calculator and recipes from the last one and goes on from there.
+// It returns the counts of file and, if ctx is done before the end of the file, ctx.Err(). It exits if the file or
+// the checkpoint cannot be read.
+func parseFile(ctx context.Context, file string, schema internal.Schema, calculator *internal.SummaryCalculator,
+	calc internal.Calculator, recipes *internal.RecipeNormalizer, cp checkpointing, isVerbose bool) (parsed,
+	ignored int, err error) {
+	if cp.path == "" {
+		parsed, ignored, err = internal.ParseContext(ctx, file, schema, calc, isVerbose)
+		if err != nil && ctx.Err() == nil {
+			log.Fatal(err)
+		}
+		return parsed, ignored, err
+	}
+
+	var from *internal.Checkpoint
+	if _, err := os.Stat(cp.path); cp.resume && !os.IsNotExist(err) {
+		last, err := internal.LoadCheckpoint(cp.path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *calculator, err = internal.RestoreSummaryCalculator(calculator.Filter, last.State); err != nil {
+			log.Fatalf("Error to restore checkpoint [file=%v]: %v", cp.path, err)
+		}
+		if recipes != nil {
+			recipes.AddNames(last.State.RecipeNames)
+		}
+		from = &last
+	}
+
+	parser := internal.CheckpointParser{Path: cp.path, Interval: cp.interval, Calculator: calculator, Calc: calc,
+		Schema: schema, Recipes: recipes}
+	parsed, ignored, err = parser.Parse(ctx, file, from, isVerbose)
+	if err != nil && ctx.Err() != nil {
+		return parsed, ignored, err
+	}
+	if err != nil {
+		log.Fatalf("Error to parse [file=%v]: %v", file, err)
+	}
+	if err := os.Remove(cp.path); err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+
+	return parsed, ignored, nil
+}
+
+// aggregateState restores the SummaryCalculator saved in stateFile, or creates one if the file does not exist, parses
+// files with it as parseFiles does and saves its state back. It exits if the state cannot be loaded or saved.
+// If ctx is done before the end of the files, the state is not saved, since the files would be added twice to it by
+// the next run, and it returns the partial aggregation and ctx.Err().
+func aggregateState(ctx context.Context, stateFile string, files []string, schema internal.Schema,
+	filter internal.Filter, recipes *internal.RecipeNormalizer, postcodes *internal.PostcodeNormalizer,
+	cp checkpointing, perFile, isVerbose bool) (internal.Aggregation, error) {
+	calculator := internal.NewSummaryCalculator(filter)
+	st := calculator.State()
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		if st, err = internal.LoadState(stateFile); err != nil {
+			log.Fatal(err)
+		}
+		if calculator, err = internal.RestoreSummaryCalculator(filter, st); err != nil {
+			log.Fatalf("Error to restore state [file=%v]: %v", stateFile, err)
+		}
+		if recipes != nil {
+			recipes.AddNames(st.RecipeNames)
+		}
+	}
+
+	summaries, parsed, ignored, err := parseFiles(ctx, files, schema, &calculator, recipes, postcodes, cp, perFile,
+		isVerbose)
+	if err != nil {
+		return aggregate(calculator, summaries, parsed+ignored, err), err
+	}
+
+	next := calculator.State()
+	next.Parsed, next.Ignored = st.Parsed+parsed, st.Ignored+ignored
+	if recipes != nil {
+		next.RecipeNames = recipes.Names()
+	}
+	if err := internal.SaveState(stateFile, next); err != nil {
+		log.Fatal(err)
+	}
+
+	return aggregate(calculator, summaries, parsed+ignored, nil), nil
+}
+
+// loadSchema parses the mapping of schemaOption and the attributes of groupOptions, if the command has them. It fails
+// if any of them is invalid.
+func (c command) loadSchema(f flags) internal.Schema {
+	schema, err := internal.ParseSchema(f[schemaMapping])
+	if err != nil {
+		c.fail(err)
+	}
+	if schema.Attributes, err = internal.ParseAttributes(f[attributes]); err != nil {
+		c.fail(err)
+	}
+
+	return schema
+}
+
+// loadFilter builds the filter of filterOptions and groupOptions. It fails if the filter is invalid or if it has a
+// field that is not in the schema of the command.
+func (c command) loadFilter(f flags) internal.Filter {
+	th, err := strconv.ParseFloat(f[threshold], 64)
+	if err != nil {
+		c.fail(fmt.Errorf("invalid threshold %v: %v", f[threshold], err))
+	}
+
+	filter := internal.Filter{
+		Postcode:  f[postcode],
+		TimeRange: f[timeRange],
+		Recipes:   strings.Split(f[names], ","),
+		MatchMode: internal.MatchMode(f[matchMode]),
+		Threshold: th,
+		FromDate:  f[fromDate],
+		ToDate:    f[toDate],
+		GroupBy:   splitList(f[groupBy]),
+		Where:     f[where],
+	}
+	if err := filter.Validate(); err != nil {
+		var fe *internal.FilterError
+		if !errors.As(err, &fe) {
+			err = fmt.Errorf("invalid filter: %v", err)
+		}
+		c.fail(err)
+	}
+	if err := filter.ValidateFields(c.loadSchema(f)); err != nil {
+		c.fail(err)
+	}
+
+	return filter
+}
+
+// loadFilterAndNormalizers builds the filter and the normalizers of normalizerOptions. The normalizers are nil with
+// --raw, otherwise the filter postcode and the postcodes of the where are normalized as well.
+func (c command) loadFilterAndNormalizers(f flags) (internal.Filter, *internal.RecipeNormalizer,
+	*internal.PostcodeNormalizer) {
+	filter := c.loadFilter(f)
+	if c.bool(f, raw) {
+		return filter, nil, nil
+	}
+
+	aliasMap := c.loadMap(f[aliases], internal.LoadAliases)
+	regionMap := c.loadMap(f[regions], internal.LoadRegions)
+	prefix := 0
+	if f[postcodeGroup] != "" {
+		var err error
+		if prefix, err = internal.ParsePostcodeGroup(f[postcodeGroup]); err != nil {
+			c.fail(err)
+		}
+	}
+
+	postcodes := internal.NewPostcodeNormalizer(prefix, regionMap)
+	filter.Postcode = postcodes.Normalize(filter.Postcode)
+	filter.Where = postcodes.NormalizeWhere(filter.Where)
+	filter.PostcodeDistribution = f[postcodeGroup] != "" || f[regions] != ""
+
+	return filter, internal.NewRecipeNormalizer(aliasMap), postcodes
+}
+
+// loadMap loads the JSON file with load if file is set.
+func (c command) loadMap(file string, load func(string) (map[string]string, error)) map[string]string {
+	if file == "" {
+		return nil
+	}
+
+	m, err := load(file)
+	if err != nil {
+		c.fail(err)
+	}
+
+	return m
+}
+
+// requiredFiles returns the files of the option name, a comma separated list of paths expanded by
+// internal.ExpandPaths. It fails if it is empty and exits if a path cannot be expanded.
+func (c command) requiredFiles(f flags, name string) []string {
+	files, err := internal.ExpandPaths(strings.Split(c.requiredFile(f, name), ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return files
+}
+
+// requiredFile returns the value of the option name. It fails if it is empty.
+func (c command) requiredFile(f flags, name string) string {
+	if f[name] == "" {
+		c.fail(fmt.Errorf("--%s is required", name))
+	}
+
+	return f[name]
+}
+
+// fail reports a usage error of c.
+func (c command) fail(err error) {
+	usageError(&c, err)
+}
+
+func concatOptions(options ...[]option) []option {
+	var all []option
+	for _, o := range options {
+		all = append(all, o...)
+	}
+
+	return all
+}
+
+// splitList splits a comma separated list and trims its values. It is nil for an empty list.
+func splitList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+	"github.com/thatisuday/clapper"
+)
+
+const (
+	program    = "recipe-aggregator"
+	configFile = "config"
+)
+
+// Exit codes of the CLI.
+const (
+	exitOK          = 0
+	exitFailure     = 1 // the command failed, e.g. the input file cannot be read or has invalid records
+	exitUsage       = 2 // the command line, an environment variable or the config file is invalid
+	exitSoftware    = 70
+	exitInterrupted = 130 // SIGINT or SIGTERM stopped the command
+)
+
+var (
+	configOption = option{name: configFile, example: "'market.yaml'", usage: "YAML file with flag values"}
+	helpOption   = option{name: help, short: "h", isBool: true, usage: "Show this help"}
+)
+
+type (
+	// option is a flag of a command. value is its default value.
+	option struct {
+		name    string
+		short   string
+		isBool  bool
+		value   string
+		example string
+		usage   string
+	}
+	// command is a subcommand of the CLI. Its help is generated from summary, description and options. The arguments
+	// that are not flags are added to the option positional, a comma separated list; they are rejected if it is empty.
+	command struct {
+		name        string
+		summary     string
+		description string
+		options     []option
+		positional  string
+		run         func(command, flags)
+	}
+	// flags are the values of the options of the parsed command, defaults included.
+	flags map[string]string
+)
+
+// commands lists the subcommands in the order they are shown by the help. The first one also runs when the CLI is
+// called with flags only.
+var commands []command
+
+func init() {
+	commands = []command{aggregateCommand, validateCommand, statsCommand, queryCommand, exploreCommand,
+		convertCommand, diffCommand, serveCommand}
+}
+
+// parseArgs registers the commands in clapper, parses args and returns the command to run with its flags. A command
+// called with --help prints its help and exits. Each flag not set in args is read from its environment variable, then
+// from the config file and then from its default value.
+func parseArgs(args []string) (command, flags) {
+	registry := clapper.NewRegistry()
+	register(registry, "", commands[0].options)
+	for _, c := range commands {
+		register(registry, c.name, c.options)
+	}
+
+	cmd, rest := commands[0], args
+	for _, c := range commands {
+		if len(args) > 0 && c.name == args[0] {
+			cmd, rest = c, args[1:]
+		}
+	}
+	if len(rest) == len(args) && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		usageError(nil, fmt.Errorf("unknown command %q", args[0]))
+	}
+	positional, err := cmd.checkArgs(rest)
+	if err != nil {
+		usageError(&cmd, err)
+	}
+
+	parsed, err := registry.Parse(args)
+	if err != nil {
+		usageError(&cmd, err)
+	}
+
+	if parsed.Flags[help].Value != "" {
+		if parsed.Name == "" {
+			printHelpAndExit()
+		}
+		cmd.printHelpAndExit()
+	}
+
+	cfg := loadConfig(parsed.Flags[configFile].Value)
+	f := make(flags, len(parsed.Flags))
+	for name, flag := range parsed.Flags {
+		env, isEnv := os.LookupEnv(internal.EnvName(name))
+		file, isFile := cfg.Value(cmd.name, name)
+		switch {
+		case flag.Value != "":
+			f[name] = flag.Value
+		case isEnv:
+			f[name] = env
+		case isFile:
+			f[name] = file
+		default:
+			f[name] = flag.DefaultValue
+		}
+	}
+	if len(positional) > 0 {
+		if f[cmd.positional] != "" {
+			positional = append([]string{f[cmd.positional]}, positional...)
+		}
+		f[cmd.positional] = strings.Join(positional, ",")
+	}
+
+	return cmd, f
+}
+
+// loadConfig loads the config file given by --config or its environment variable. Otherwise it loads the file of
+// internal.DefaultConfigPath if it exists. It prints the help if the file cannot be loaded or has unknown options.
+func loadConfig(path string) internal.Config {
+	if path == "" {
+		path = os.Getenv(internal.EnvName(configFile))
+	}
+	if path == "" {
+		path = internal.DefaultConfigPath()
+		if _, err := os.Stat(path); path == "" || os.IsNotExist(err) {
+			return internal.Config{}
+		}
+	}
+
+	cfg, err := internal.LoadConfig(path)
+	if err != nil {
+		usageError(nil, err)
+	}
+
+	for name := range cfg.Values {
+		if !hasOption(commands, name) {
+			usageError(nil, fmt.Errorf("unknown option %v in config [file=%v]", name, path))
+		}
+	}
+	for section, values := range cfg.Sections {
+		for _, c := range commands {
+			if c.name != section {
+				continue
+			}
+			for name := range values {
+				if !hasOption([]command{c}, name) {
+					usageError(nil, fmt.Errorf("unknown option %v.%v in config [file=%v]", section, name, path))
+				}
+			}
+			section = ""
+		}
+		if section != "" {
+			usageError(nil, fmt.Errorf("unknown command %v in config [file=%v]", section, path))
+		}
+	}
+
+	return cfg
+}
+
+func hasOption(commands []command, name string) bool {
+	for _, c := range commands {
+		for _, o := range c.options {
+			if o.name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// register registers the command name with options. A command or option registered twice is a bug of the command
+// declarations, so it exits with exitSoftware.
+func register(registry clapper.Registry, name string, options []option) {
+	c, exists := registry.Register(name)
+	if exists {
+		fmt.Fprintf(os.Stderr, "Command %q registered twice\n", name)
+		os.Exit(exitSoftware)
+	}
+
+	for _, o := range append(options, configOption, helpOption) {
+		if _, exists := c.AddFlag(o.name, o.short, o.isBool, o.value); exists {
+			fmt.Fprintf(os.Stderr, "Flag --%s of command %q registered twice\n", o.name, name)
+			os.Exit(exitSoftware)
+		}
+	}
+}
+
+// checkArgs reports unknown flags and what clapper silently ignores: a flag without its value and, unless c takes
+// them, arguments that are not flags. It returns the arguments that are not flags.
+func (c command) checkArgs(args []string) ([]string, error) {
+	isBool := make(map[string]bool)
+	for _, o := range append(c.options, configOption, helpOption) {
+		isBool["--"+o.name] = o.isBool
+		if o.short != "" {
+			isBool["-"+o.short] = o.isBool
+		}
+	}
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			if c.positional == "" {
+				return nil, fmt.Errorf("unexpected argument %q, values must follow a flag", arg)
+			}
+			positional = append(positional, arg)
+			continue
+		}
+		name := strings.SplitN(arg, "=", 2)[0]
+		isBoolFlag, ok := isBool[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown flag %s", name)
+		}
+		if strings.Contains(arg, "=") || isBoolFlag {
+			continue
+		}
+		if i+1 < len(args) && isNumber(args[i+1]) && strings.HasPrefix(args[i+1], "-") {
+			return nil, fmt.Errorf("flag %s cannot take the negative value %s", arg, args[i+1])
+		}
+		if i+1 >= len(args) || strings.HasPrefix(args[i+1], "-") {
+			return nil, fmt.Errorf("flag %s needs a value", arg)
+		}
+		i++
+	}
+
+	return positional, nil
+}
+
+// usageError prints err pointing at the invalid value of an *internal.FilterError and how to get the help of c, or
+// the general help if c is nil. Then it exits with exitUsage.
+func usageError(c *command, err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+	var fe *internal.FilterError
+	if errors.As(err, &fe) {
+		pad := strings.Repeat(" ", utf8.RuneCountInString(fe.Value[:fe.Offset]))
+		fmt.Fprintf(os.Stderr, "\t%s\n\t%s^\n", fe.Value, pad)
+	}
+
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "Run \"%s --help\" for usage.\n", program)
+	} else {
+		fmt.Fprintf(os.Stderr, "Run \"%s %s --help\" for usage.\n", program, c.name)
+	}
+	os.Exit(exitUsage)
+}
+
+// interruptContext returns a context that is done on SIGINT or SIGTERM. Then the signals are no longer caught, so
+// a second one kills the process at once.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return ctx, stop
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+
+	return err == nil
+}
+
+// bool returns the value of the boolean option name, false if it is empty. It fails if the value is not a boolean
+// of strconv.ParseBool, e.g. "yes" from the environment.
+func (c command) bool(f flags, name string) bool {
+	if f[name] == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(f[name])
+	if err != nil {
+		c.fail(fmt.Errorf("invalid --%s %v, want true or false", name, f[name]))
+	}
+
+	return b
+}
+
+// printHelpAndExit prints the commands and exits with exitOK.
+func printHelpAndExit() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Usage:\n\t%s <command> [flags]\n\t%s [flags]\t(same as %s %s)\n\nCommands:\n", program,
+		program, program, commands[0].name)
+	for _, c := range commands {
+		fmt.Fprintf(w, "\t%s\t%s\n", c.name, c.summary)
+	}
+	w.Flush()
+
+	fmt.Printf("\nUse \"%s <command> --help\" for more information about a command.\n", program)
+	fmt.Printf(`
+Flag values are resolved in this order: command line > environment > config file > built-in default.
+The environment variable of a flag is %s followed by its name in upper case with underscores, e.g.
+%s. The config file is --config, %s or else
+%s.
+It is a YAML file of flag names and values. A section named after a command overrides them for that command. E.g.:
+	postcode: "10120"
+	names: [Potato, Veggie]
+	serve:
+	  addr: ":9090"
+
+Exit status: %d on success, %d if the command fails, %d for usage errors, such as an invalid flag value, and %d if
+the command is interrupted by SIGINT or SIGTERM.
+`, internal.EnvPrefix, internal.EnvName(matchMode), internal.EnvName(configFile), internal.DefaultConfigPath(),
+		exitOK, exitFailure, exitUsage, exitInterrupted)
+	os.Exit(exitOK)
+}
+
+// printHelpAndExit prints the description and the options of c and exits with exitOK.
+func (c command) printHelpAndExit() {
+	fmt.Printf("Usage:\n\t%s %s [flags]\n\n%s\n\nFlags:\n", program, c.name, strings.TrimSpace(c.description))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tName\tType\tExample\tDefault\tDescription")
+	for _, o := range append(c.options, configOption, helpOption) {
+		name := "--" + o.name
+		if o.short != "" {
+			name = "-" + o.short + ", " + name
+		}
+		kind, example, value := "string", o.example, o.value
+		if o.isBool {
+			kind = "flag"
+		}
+		if example == "" {
+			example = "NA"
+		}
+		if value == "" {
+			value = "NA"
+		} else {
+			value = "'" + value + "'"
+		}
+		fmt.Fprintf(w, "\t%s\t%s\t%s\t%s\t%s\n", name, kind, example, value, o.usage)
+	}
+	w.Flush()
+	os.Exit(exitOK)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+const output = "output"
+
+var convertCommand = command{
+	name:    "convert",
+	summary: "Convert JSON files into a columnar file that is read without decoding JSON",
+	description: `
+Converts the records of JSON files into a compact binary columnar file: the strings are dictionary-encoded, the ids
+and counts are varints and the delivery windows are already parsed. Its header has a checksum of the content. The
+commands that read the records, such as aggregate, stats, query, explore and serve --dataset, detect the columnar
+file by its header and read it directly, so a large input is decoded once and queried many times. E.g.:
+	./recipe-aggregator convert -f 'exports/2026-10-*.json' -o 'october.rcc'
+	./recipe-aggregator aggregate -f 'october.rcc' -p '10120'
+
+Only the valid records are written, as they are decoded by --schema and with the attributes of --attributes; the
+number of invalid records is kept, so the parsed and ignored counts of the columnar file are the ones of the JSON
+files. The records are not normalized. Checkpoints and validate need the JSON files.`,
+	options: []option{
+		filesOption,
+		{name: output, short: "o", example: "'october.rcc'", usage: "Columnar file written (required)"},
+		schemaOption,
+		attributesOption,
+	},
+	positional: filepath,
+	run:        runConvert,
+}
+
+func runConvert(c command, f flags) {
+	files := c.requiredFiles(f, filepath)
+	out := c.requiredFile(f, output)
+	schema := c.loadSchema(f)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	dataset, parsed, ignored, err := internal.LoadDataset(ctx, files, schema, nil, nil)
+	if err != nil && ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Interrupted after %d records: %v\n", parsed, err)
+		os.Exit(exitInterrupted)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		log.Fatalf("Error to write [file=%v]: %v", out, err)
+	}
+	err = dataset.WriteColumnar(file, ignored)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out)
+		log.Fatalf("Error to write [file=%v]: %v", out, err)
+	}
+	fmt.Printf("Converted %d records (%d ignored) into %s\n", parsed, ignored, out)
+}
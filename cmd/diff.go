@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+const (
+	base   = "base"
+	format = "format"
+)
+
+var diffCommand = command{
+	name:    "diff",
+	summary: "Compare the aggregations of two JSON files",
+	description: `
+Compares the aggregation of the base file to the one of the file and prints the change of the delivery count and
+its percentage of each recipe, the recipes that appeared or disappeared, the change of the busiest postcode and of
+the deliveries counted by the postcode, time range and names filters. E.g.:
+	./recipe-aggregator diff -b 'last_week.json' -f 'this_week.json' --format text
+
+Each file is either a JSON file of records, aggregated with the schema, filter and normalizer flags, or an
+aggregation saved from the aggregate command, e.g. 'test/output.json', which is compared as it is.
+
+Format is json or text, a human-readable summary followed by a table of the recipes.`,
+	options: concatOptions([]option{
+		{name: base, short: "b", example: "'last_week.json'", usage: "JSON file compared against (required)"},
+		fileOption,
+		schemaOption,
+		{name: format, value: "json", example: "'text'", usage: "Output format: json or text"},
+	}, filterOptions, normalizerOptions),
+	run: runDiff,
+}
+
+func runDiff(c command, f flags) {
+	baseFile := c.requiredFile(f, base)
+	file := c.requiredFile(f, filepath)
+	if f[format] != "json" && f[format] != "text" {
+		c.fail(fmt.Errorf("invalid format %q, want json or text", f[format]))
+	}
+	schema := c.loadSchema(f)
+	filter, recipes, postcodes := c.loadFilterAndNormalizers(f)
+
+	diff := internal.DiffAggregations(loadAggregation(baseFile, schema, filter, recipes, postcodes),
+		loadAggregation(file, schema, filter, recipes, postcodes))
+	if f[format] == "text" {
+		fmt.Print(diff.Text())
+		return
+	}
+	fmt.Println(diff)
+}
+
+// loadAggregation reads file if it is a saved aggregation, otherwise it aggregates its records decoded with schema.
+func loadAggregation(file string, schema internal.Schema, filter internal.Filter, recipes *internal.RecipeNormalizer,
+	postcodes *internal.PostcodeNormalizer) internal.Aggregation {
+	a, ok, err := internal.ReadAggregation(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitFailure)
+	}
+	if ok {
+		return a
+	}
+
+	a, _ = aggregateFiles(context.Background(), []string{file}, schema, filter, recipes, postcodes, checkpointing{},
+		false, false)
+
+	return a
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+var exploreCommand = command{
+	name:    "explore",
+	summary: "Explore the aggregations of JSON files interactively",
+	description: `
+Loads the records of JSON files once into an in-memory dataset, indexed by postcode and weekday, and shows the
+delivery count of the postcode and time range, the recipes matched by name, the busiest postcode and the top recipes.
+Commands typed at the prompt change the filter and the aggregation is shown again at once, without reading the files
+again. E.g.:
+	./recipe-aggregator explore -f 'test/hf_test_calculation_fixtures.json' -p '10120'
+	> r Friday 10AM - 2PM
+	> n Veggie,Potato
+
+The commands are p (postcode), r (timerange), n (names), m (match-mode), t (threshold), w (where), top, help and
+quit. The flags set the filter shown first; files, schema and normalizers are given as in aggregate.`,
+	options: concatOptions([]option{filesOption, schemaOption, attributesOption}, filterOptions,
+		normalizerOptions),
+	positional: filepath,
+	run:        runExplore,
+}
+
+func runExplore(c command, f flags) {
+	files := c.requiredFiles(f, filepath)
+	schema := c.loadSchema(f)
+	filter, recipes, postcodes := c.loadFilterAndNormalizers(f)
+
+	// Signals are only caught while loading, so they stop the explorer as usual afterwards.
+	ctx, stop := interruptContext()
+	fmt.Printf("Loading %s\n", strings.Join(files, ", "))
+	dataset, parsed, _, err := internal.LoadDataset(ctx, files, schema, recipes, postcodes)
+	if err != nil && ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Interrupted after %d records: %v\n", parsed, err)
+		os.Exit(exitInterrupted)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	stop()
+
+	explorer := internal.NewExplorer(dataset, filter, schema, postcodes, parsed)
+	if err := explorer.Run(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println()
+}
@@ -1,126 +1,37 @@
 package main
 
 import (
-	"fmt"
-	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
-	"github.com/thatisuday/clapper"
 	"os"
-	"strconv"
-	"strings"
-	"time"
 )
 
-var (
-	defaultPostcode  = "10120"
-	defaultTimeRange = "10AM - 3PM"
-	defaultNames     = "Potato,Veggie,Mushroom"
-	filter           internal.Filter
-	file             string
-	isVerbose        bool
-)
-
-//Example of use:
-//./recipe-aggregator -f 'test/hf_test_calculation_fixtures.json' -r 'Friday 10AM - 2PM' -p '10021' -n 'Veggie,Potato'
+// Recipe-aggregator aggregates the recipe deliveries of a JSON file.
+//
+// Example of use:
+// ./recipe-aggregator aggregate -f 'test/hf_test_calculation_fixtures.json' -r 'Friday 10AM - 2PM' -p '10021' -n 'Veggie,Potato'
 //
-//Only filename parameter is required. The filter (postcode, timerange and names) are optional.
+// Commands:
 //
-//List of parameters:
+// aggregate | Aggregate the records of JSON files
+// validate  | Check the records of a JSON file without aggregating them
+// stats     | Print quick counts of JSON files
+// query     | Run a SQL-like query over the records of JSON files
+// explore   | Explore the aggregations of JSON files interactively
+// convert   | Convert JSON files into a columnar file that is read without decoding JSON
+// diff      | Compare the aggregations of two JSON files
+// serve     | Serve the aggregation over HTTP and gRPC
 //
-//Name      | Type   | Name        | Shortname | Example 									| Required | Default
-//Filename  | string | --filename  | -f        | "test/hf_test_calculation_fixtures.json" | true     | NA
-//Postcode  | string | --postcode  | -p        | '10021'                                  | false    | '10120'
-//Timerange | string | --timerange | -r        | 'Friday 10AM - 2PM'                      | false    | '10AM - 3PM'
-//Names     | string | --names     | -n        | 'Veggie,Potato'                          | false    | 'Potato,Veggie,Mushroom'
-//Verbose   | flag   | --verbose   | -v        | NA                                       | false    | NA
-//Help      | flag   | --help      | -h        | NA                                       | false    | NA
+// Calling it with flags only, as in ./recipe-aggregator -f 'test/hf_test_calculation_fixtures.json', is the same as
+// calling aggregate. Use ./recipe-aggregator <command> --help for the description and the flags of each command; the
+// help is generated from the options of the commands declared in this package.
+//
+// Flags not set in the command line are read from RECIPE_AGGREGATOR_<FLAG> environment variables, then from a YAML
+// config file (--config or $XDG_CONFIG_HOME/recipe-aggregator/config.yaml) and then from the built-in defaults.
+//
+// Flags are validated before running a command: for instance, the timerange must have a begin hour followed by AM
+// and an end hour followed by PM, e.g. "10AM - 3PM". The exit status is 0 on success, 1 if the command fails, 2 for
+// usage errors and 130 if the command is interrupted by SIGINT or SIGTERM. An interrupted aggregate prints the
+// aggregation of the records read so far with "partial": true.
 func main() {
-	loadArgs()
-	start := time.Now()
-	if isVerbose {
-		fmt.Printf("Input\nFile: %v\nFilter: %v\n", file, filter)
-	}
-
-	calculator := internal.NewSummaryCalculator(filter)
-	internal.Parse(file, &calculator, isVerbose)
-	aggregation := calculator.Aggregate()
-	fmt.Printf(internal.ConsoleClear)
-	fmt.Println(aggregation)
-
-	duration := time.Since(start)
-	if isVerbose {
-		fmt.Println(duration)
-	}
-}
-
-func loadArgs() {
-	var m = make(map[string]string)
-	const (
-		filepath = "filepath"
-		postcode = "postcode"
-		timeRange = "timerange"
-		names = "names"
-		verbose = "verbose"
-		help = "help"
-	)
-
-	registry := clapper.NewRegistry()
-	rootCommand, _ := registry.Register("")
-	rootCommand.AddFlag(filepath, "f", false, "")
-	rootCommand.AddFlag(postcode, "p", false, defaultPostcode)
-	rootCommand.AddFlag(timeRange, "r", false, defaultTimeRange)
-	rootCommand.AddFlag(names, "n", false, defaultNames)
-	rootCommand.AddFlag(verbose, "v", true, "")
-	rootCommand.AddFlag(help, "h", true, "")
-
-	command, err := registry.Parse(os.Args[1:])
-
-	if err != nil {
-		printHelpAndExit()
-	}
-
-	for flagName, flagValue := range command.Flags {
-		if flagValue.Value != "" {
-			m[flagName] = flagValue.Value
-		} else {
-			m[flagName] = flagValue.DefaultValue
-		}
-	}
-
-	if ok, _ := strconv.ParseBool(m[help]); ok {
-		printHelpAndExit()
-	}
-
-	verb, err := strconv.ParseBool(m[verbose])
-	isVerbose = err == nil && verb
-
-	filter = internal.Filter{
-		Postcode:  m[postcode],
-		TimeRange: m[timeRange],
-		Recipes:   strings.Split(m[names], ","),
-	}
-
-	file = m[filepath]
-	if file == "" {
-		printHelpAndExit()
-	}
+	cmd, f := parseArgs(os.Args[1:])
+	cmd.run(cmd, f)
 }
-
-func printHelpAndExit() {
-	fmt.Println(exampleOfUsage)
-	os.Exit(0)
-}
-const exampleOfUsage = `
-Example of use:
-	./recipe-aggregator -f 'test/hf_test_calculation_fixtures.json' -r 'Friday 10AM - 2PM' -p '10021' -n 'Veggie,Potato'
-
-Only filename parameter is required. The filter (postcode, timerange and names) are optional.
-
-List of parameters:
-
-Name      | Type   | Name        | Shortname | Example 									| Required | Default  
-Filename  | string | --filename  | -f        | "test/hf_test_calculation_fixtures.json" | true     | NA
-Postcode  | string | --postcode  | -p        | '10021'                                  | false    | '10120'
-Timerange | string | --timerange | -r        | 'Friday 10AM - 2PM'                      | false    | '10AM - 3PM'
-Names     | string | --names     | -n        | 'Veggie,Potato'                          | false    | 'Potato,Veggie,Mushroom'
-Verbose   | flag   | --verbose   | -v        | NA                                       | false    | NA
-Help      | flag   | --help      | -h        | NA                                       | false    | NA`
\ No newline at end of file
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+const query = "query"
+
+var queryCommand = command{
+	name:    "query",
+	summary: "Run a SQL-like query over the records of JSON files",
+	description: `
+Runs a SQL-like query over the valid records of JSON files and prints its rows. The query is a single argument or
+the --query flag. E.g.:
+	./recipe-aggregator query "SELECT recipe, count(*) FROM deliveries WHERE postcode = '10120' GROUP BY recipe ORDER BY 2 DESC LIMIT 5" -f 'week.json'
+
+The clauses are SELECT, FROM deliveries, WHERE, GROUP BY, ORDER BY and LIMIT, in this order. SELECT takes fields,
+count(*) or *, which is postcode, recipe, weekday, from and to, each one optionally renamed by AS. The fields are
+postcode, recipe, delivery, delivery_date, weekday, from and to, the hours of the delivery in 24-hour format, and
+the attributes. WHERE takes a condition as the --where of aggregate. The columns of a query with GROUP BY or
+count(*) must be counts or fields grouped by. ORDER BY takes columns, by name or position starting at 1, each one
+optionally followed by ASC or DESC. Keywords are case insensitive.
+
+Records are read one at a time: a query without GROUP BY, count(*) and ORDER BY prints its rows as they are found
+and stops reading at its LIMIT. Files, schema and attributes are given as in aggregate.
+
+Format is text, a table, or csv.`,
+	options: []option{
+		{name: query, short: "q", example: `"SELECT weekday, count(*) FROM deliveries GROUP BY weekday"`,
+			usage: "Query to run (required)"},
+		filesOption,
+		schemaOption,
+		attributesOption,
+		{name: format, value: "text", example: "'csv'", usage: "Output format: text or csv"},
+	},
+	positional: query,
+	run:        runQuery,
+}
+
+func runQuery(c command, f flags) {
+	q, err := internal.ParseQuery(c.requiredFile(f, query))
+	if err != nil {
+		c.fail(err)
+	}
+	files := c.requiredFiles(f, filepath)
+	schema := c.loadSchema(f)
+	if err := q.ValidateFields(schema); err != nil {
+		c.fail(err)
+	}
+	if f[format] != "text" && f[format] != "csv" {
+		c.fail(fmt.Errorf("invalid format %q, want text or csv", f[format]))
+	}
+
+	write, flush := tableWriter()
+	if f[format] == "csv" {
+		write, flush = csvWriter()
+	}
+	write(q.Columns())
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	err = internal.RunQuery(ctx, q, files, schema, write)
+	flush()
+	if err != nil && err == ctx.Err() {
+		fmt.Fprintf(os.Stderr, "Interrupted: %v\n", err)
+		os.Exit(exitInterrupted)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// tableWriter returns a function writing the rows of a table aligned by columns and the function flushing them.
+func tableWriter() (func(row []string), func()) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	return func(row []string) {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}, func() { w.Flush() }
+}
+
+// csvWriter returns a function writing rows as CSV and the function flushing them.
+func csvWriter() (func(row []string), func()) {
+	w := csv.NewWriter(os.Stdout)
+
+	return func(row []string) {
+		if err := w.Write(row); err != nil {
+			log.Fatal(err)
+		}
+	}, w.Flush
+}
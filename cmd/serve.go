@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+const (
+	addr            = "addr"
+	maxBody         = "max-body"
+	maxUpload       = "max-upload"
+	dataDir         = "data-dir"
+	jobsDir         = "jobs-dir"
+	maxJobs         = "max-jobs"
+	grpcAddr        = "grpc-addr"
+	readTimeout     = "read-timeout"
+	writeTimeout    = "write-timeout"
+	shutdownTimeout = "shutdown-timeout"
+	datasetFiles    = "dataset"
+)
+
+var serveCommand = command{
+	name:    "serve",
+	summary: "Serve the aggregation over HTTP and gRPC",
+	description: `
+Starts an HTTP server instead of reading a file. E.g.:
+	./recipe-aggregator serve --addr ':8080' -a 'aliases.json'
+
+It exposes POST /aggregate. The body is a JSON array of records or NDJSON (one record per line) and the filter is
+set by the query parameters postcode, timerange, names, match_mode, threshold, from, to, where, group_by,
+postcode_group and raw. The filter flags of serve are the defaults of the requests. E.g.:
+	curl -X POST --data-binary @test/hf_test_calculation_fixtures.json 'localhost:8080/aggregate?postcode=10021'
+
+Large files are aggregated by jobs. POST /jobs uploads the body, or reads the file at the path query parameter inside
+--data-dir, and responds the job ID. GET /jobs/{id} responds the progress, GET /jobs/{id}/result the aggregation and
+DELETE /jobs/{id} cancels the job. Jobs are kept in memory unless --jobs-dir is set; only the last --max-jobs ended
+jobs are kept in memory. E.g.:
+	curl -X POST 'localhost:8080/jobs?path=hf_test_calculation_fixtures.json&postcode=10021'
+
+--grpc-addr also serves the Aggregator gRPC service of internal/pb/aggregator.proto, where clients stream records
+and receive the aggregation when the stream is closed, or partial aggregations periodically with WatchAggregate.
+
+--dataset loads files once into an in-memory dataset, indexed by postcode and weekday, and exposes GET /dataset. It
+aggregates the dataset with the filter of the query parameters, except postcode_group and raw. E.g.:
+	curl 'localhost:8080/dataset?postcode=10021&where=weekday+%3D+Monday'`,
+	options: concatOptions([]option{
+		{name: addr, value: ":8080", example: "':9090'", usage: "HTTP address"},
+		{name: maxBody, value: strconv.Itoa(internal.DefaultMaxBodyBytes), example: "'1048576'",
+			usage: "Maximum bytes of a POST /aggregate body"},
+		{name: maxUpload, value: strconv.Itoa(internal.DefaultMaxUploadBytes), example: "'1048576'",
+			usage: "Maximum bytes of a job upload"},
+		{name: dataDir, example: "'data'", usage: "Directory of the files jobs read by path"},
+		{name: jobsDir, example: "'jobs'", usage: "Directory where jobs are kept"},
+		{name: maxJobs, value: strconv.Itoa(internal.DefaultMaxEndedJobs), example: "'100'",
+			usage: "Maximum ended jobs kept in memory, the oldest are removed first"},
+		{name: grpcAddr, example: "':9091'", usage: "gRPC address"},
+		{name: datasetFiles, example: "'exports/2026-10-*.json'",
+			usage: "Comma separated JSON files, directories or glob patterns loaded once and aggregated by GET /dataset"},
+		{name: readTimeout, value: internal.DefaultReadTimeout.String(), example: "'30s'",
+			usage: "Maximum duration to read a request"},
+		{name: writeTimeout, value: internal.DefaultWriteTimeout.String(), example: "'30s'",
+			usage: "Maximum duration to write a response"},
+		{name: shutdownTimeout, value: internal.DefaultShutdownTimeout.String(), example: "'10s'",
+			usage: "Maximum duration to wait for the requests in progress on shutdown"},
+	}, filterOptions, []option{aliasesOption, regionsOption}),
+	run: runServe,
+}
+
+func runServe(c command, f flags) {
+	cfg := c.loadServerConfig(f)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	grpcErrs := make(chan error, 1)
+	if f[grpcAddr] != "" {
+		fmt.Printf("Listening gRPC on %v\n", f[grpcAddr])
+		go func() {
+			err := internal.ServeGRPC(ctx, f[grpcAddr], cfg)
+			if err != nil {
+				stop()
+			}
+			grpcErrs <- err
+		}()
+	} else {
+		grpcErrs <- nil
+	}
+
+	fmt.Printf("Listening on %v\n", cfg.Addr)
+	if err := internal.Serve(ctx, cfg); err != nil {
+		log.Fatalf("Error to serve [addr=%v]: %v", cfg.Addr, err)
+	}
+	if err := <-grpcErrs; err != nil {
+		log.Fatalf("Error to serve gRPC [addr=%v]: %v", f[grpcAddr], err)
+	}
+}
+
+func (c command) loadServerConfig(f flags) internal.ServerConfig {
+	cfg := internal.ServerConfig{
+		Addr:          f[addr],
+		DataDir:       f[dataDir],
+		JobsDir:       f[jobsDir],
+		DefaultFilter: c.loadFilter(f),
+		Aliases:       c.loadMap(f[aliases], internal.LoadAliases),
+		Regions:       c.loadMap(f[regions], internal.LoadRegions),
+	}
+
+	var err error
+	for _, s := range []struct {
+		name string
+		dst  *int64
+	}{{maxBody, &cfg.MaxBodyBytes}, {maxUpload, &cfg.MaxUploadBytes}} {
+		if *s.dst, err = strconv.ParseInt(f[s.name], 10, 64); err != nil || *s.dst <= 0 {
+			c.fail(fmt.Errorf("invalid --%s %v", s.name, f[s.name]))
+		}
+	}
+	if cfg.MaxEndedJobs, err = strconv.Atoi(f[maxJobs]); err != nil || cfg.MaxEndedJobs <= 0 {
+		c.fail(fmt.Errorf("invalid --%s %v", maxJobs, f[maxJobs]))
+	}
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{{readTimeout, &cfg.ReadTimeout}, {writeTimeout, &cfg.WriteTimeout}, {shutdownTimeout, &cfg.ShutdownTimeout}} {
+		if *d.dst, err = time.ParseDuration(f[d.name]); err != nil || *d.dst <= 0 {
+			c.fail(fmt.Errorf("invalid --%s %v", d.name, f[d.name]))
+		}
+	}
+
+	if f[datasetFiles] != "" {
+		files := c.requiredFiles(f, datasetFiles)
+		fmt.Printf("Loading %s\n", strings.Join(files, ", "))
+		dataset, parsed, _, err := internal.LoadDataset(context.Background(), files, internal.DefaultSchema,
+			internal.NewRecipeNormalizer(cfg.Aliases), internal.NewPostcodeNormalizer(0, cfg.Regions))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Loaded %d records\n", parsed)
+		cfg.Dataset = dataset
+	}
+
+	return cfg
+}
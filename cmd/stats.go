@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+var statsCommand = command{
+	name:    "stats",
+	summary: "Print quick counts of JSON files",
+	description: `
+Prints the number of records, valid and invalid records, distinct recipes, distinct postcodes and deliveries per
+weekday of JSON files. It is faster than aggregate because nothing is filtered or sorted. Names and postcodes are
+normalized as in aggregate unless --raw is set. Files and schema are given as in aggregate. E.g.:
+	./recipe-aggregator stats -f 'test/hf_test_calculation_fixtures.json'`,
+	options: []option{filesOption, schemaOption, aliasesOption, {name: raw, isBool: true,
+		usage: "Count names and postcodes as they are in the file"}, verboseOption},
+	positional: filepath,
+	run:        runStats,
+}
+
+func runStats(c command, f flags) {
+	files := c.requiredFiles(f, filepath)
+	schema := c.loadSchema(f)
+	calculator := internal.NewStatsCalculator()
+	var calc internal.Calculator = calculator
+	if !c.bool(f, raw) {
+		calc = internal.NewNormalizingCalculator(calc, internal.NewRecipeNormalizer(c.loadMap(f[aliases],
+			internal.LoadAliases)), internal.NewPostcodeNormalizer(0, nil))
+	}
+
+	var parsed, ignored int
+	for _, file := range files {
+		p, i, err := internal.ParseContext(context.Background(), file, schema, calc, c.bool(f, verbose))
+		if err != nil {
+			log.Fatal(err)
+		}
+		parsed, ignored = parsed+p, ignored+i
+	}
+	out, _ := json.MarshalIndent(calculator.Stats(parsed, ignored), "", "    ")
+	fmt.Printf(internal.ConsoleClear)
+	fmt.Println(string(out))
+}
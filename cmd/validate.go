@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal"
+)
+
+const maxProblems = "max-problems"
+
+var validateCommand = command{
+	name:    "validate",
+	summary: "Check the records of a JSON file without aggregating them",
+	description: `
+Checks every record of a JSON file and prints a JSON report with the number of valid and invalid records and why
+the first invalid records are invalid. It exits with status 1 if any record is invalid or the file is not a valid
+JSON. E.g.:
+	./recipe-aggregator validate -f 'test/hf_test_calculation_fixtures.json' --max-problems 10
+
+Schema maps the record fields to other JSON keys as in aggregate.`,
+	options: []option{
+		fileOption,
+		schemaOption,
+		{name: maxProblems, value: "100", example: "'10'", usage: "Maximum number of problems reported, 0 for all"},
+	},
+	run: runValidate,
+}
+
+func runValidate(c command, f flags) {
+	file := c.requiredFile(f, filepath)
+	schema := c.loadSchema(f)
+	max, err := strconv.Atoi(f[maxProblems])
+	if err != nil || max < 0 {
+		c.fail(fmt.Errorf("invalid --%s %v, it must be a number greater than or equal to 0", maxProblems,
+			f[maxProblems]))
+	}
+	if max == 0 {
+		max = -1
+	}
+
+	in, err := os.Open(file)
+	if err != nil {
+		fmt.Printf("Error to read [file=%v]: %v\n", file, err)
+		os.Exit(exitFailure)
+	}
+	defer in.Close()
+
+	report, err := internal.Validate(in, schema, max)
+	out, _ := json.MarshalIndent(report, "", "    ")
+	fmt.Println(string(out))
+	if err != nil {
+		fmt.Printf("Error to parse [file=%v]: %v\n", file, err)
+	}
+	if err != nil || report.Invalid > 0 {
+		in.Close()
+		os.Exit(exitFailure)
+	}
+}
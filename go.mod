@@ -2,4 +2,10 @@ module github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020
 
 go 1.16
 
-require github.com/thatisuday/clapper v1.0.10
+require (
+	github.com/thatisuday/clapper v1.0.10
+	golang.org/x/text v0.3.7
+	google.golang.org/grpc v1.46.2
+	google.golang.org/protobuf v1.28.0
+	gopkg.in/yaml.v2 v2.4.0
+)
@@ -7,30 +7,65 @@ import (
 type (
 	// NamesMatches groups recipe names that matched filter slice names criteria.
 	NamesMatches []string
+	// NameMatch details a recipe name that matched filter slice names criteria. Score is the best similarity between
+	// the name and the filter names, from 0 to 1, Terms are the filter names that matched it and DeliveryCount is how
+	// many times it appears in the input JSON file.
+	NameMatch struct {
+		Recipe        string   `json:"recipe"`
+		Score         float64  `json:"score"`
+		Terms         []string `json:"terms"`
+		DeliveryCount int      `json:"delivery_count"`
+	}
 	// RecipeCount is an abstraction for each distinct recipe that is in the input JSON file.
 	RecipeCount struct {
 		Recipe string `json:"recipe"`
-		Count  int `json:"count"`
+		Count  int    `json:"count"`
 	}
 	// BusiestPostcode represents the postcode with more appearances in the input JSON file.
 	BusiestPostcode struct {
 		Postcode      string `json:"postcode"`
-		DeliveryCount int `json:"delivery_count"`
+		DeliveryCount int    `json:"delivery_count"`
+	}
+	// PostcodeCount is the delivery count of a postcode or of a postcode group.
+	PostcodeCount struct {
+		Postcode      string `json:"postcode"`
+		DeliveryCount int    `json:"delivery_count"`
+	}
+	// DateCount is the delivery count of a date. E.g. "2026-10-01".
+	DateCount struct {
+		Date          string `json:"date"`
+		DeliveryCount int    `json:"delivery_count"`
+	}
+	// GroupCount is the delivery count of a group: a combination of the values of the Filter.GroupBy fields, by field.
+	GroupCount struct {
+		Group         map[string]string `json:"group"`
+		DeliveryCount int               `json:"delivery_count"`
 	}
 	// PostcodeAndTimeCount counts how many times the recipes that matches filter criteria appears in the input JSON file.
 	PostcodeAndTimeCount struct {
 		Postcode      string `json:"postcode"`
 		From          string `json:"from"`
 		To            string `json:"to"`
-		DeliveryCount int `json:"delivery_count"`
+		DeliveryCount int    `json:"delivery_count"`
 	}
-	// Aggregation groups all information needed in output file.
+	// Aggregation groups all information needed in output file. Partial is true if the parsing was interrupted and
+	// RecordCount is the number of records read until then. Files has the summary of each input file when requested.
+	// DateCount has the delivery count of each date, only if the records have a delivery date. GroupCount has the
+	// delivery count of each group of Filter.GroupBy.
 	Aggregation struct {
 		UniqueRecipeName     int           `json:"unique_recipe_count"`
 		RecipeCount          []RecipeCount `json:"count_per_recipe"`
 		BusiestPostcode      `json:"busiest_postcode"`
 		PostcodeAndTimeCount `json:"count_per_postcode_and_time"`
-		NameMatches          NamesMatches `json:"match_by_name"`
+		NameMatches          NamesMatches    `json:"match_by_name"`
+		NameMatchDetails     []NameMatch     `json:"match_by_name_details,omitempty"`
+		NameMatchesCount     int             `json:"match_by_name_delivery_count,omitempty"`
+		PostcodeCount        []PostcodeCount `json:"count_per_postcode,omitempty"`
+		DateCount            []DateCount     `json:"count_per_date,omitempty"`
+		GroupCount           []GroupCount    `json:"count_per_group,omitempty"`
+		Partial              bool            `json:"partial,omitempty"`
+		RecordCount          int             `json:"record_count,omitempty"`
+		Files                []FileSummary   `json:"files,omitempty"`
 	}
 )
 
@@ -1,20 +1,39 @@
 package internal
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 )
 
+// groupSeparator joins the values of a group into a key of SummaryCalculator.groupCounts. It is the ASCII unit
+// separator, which is not expected in the values.
+const groupSeparator = "\x1f"
+
 type (
 	// Calculator is the interface that calculates the aggregation given a Record.
 	Calculator interface {
 		Calculate(r Record)
 	}
-	// Filter is the information needed to matches PostcodeAndTimeCount and NamesMatches.
+	// Filter is the information needed to matches PostcodeAndTimeCount and NamesMatches. MatchMode selects how Recipes
+	// are compared against recipe names and Threshold is the minimum similarity accepted by FuzzyMatch, greater than
+	// 0 and at most 1; it has no default here, see DefaultFuzzyThreshold, and the other modes ignore it.
+	// PostcodeDistribution adds the delivery count of every postcode to the Aggregation. FromDate and ToDate, both
+	// inclusive and optional, restrict the aggregation to the records delivered between them. E.g. "2026-10-01".
+	// GroupBy adds the delivery count of each combination of the values of its fields, either Record fields or
+	// attributes. E.g. ["country", "box_size"]. Where is a condition of ParseWhere that the records must meet to be
+	// aggregated.
 	Filter struct {
-		Postcode  string
-		TimeRange string
-		Recipes   []string
+		Postcode             string    `json:"postcode"`
+		TimeRange            string    `json:"timerange"`
+		Recipes              []string  `json:"names"`
+		MatchMode            MatchMode `json:"match_mode"`
+		Threshold            float64   `json:"threshold"`
+		PostcodeDistribution bool      `json:"postcode_distribution"`
+		FromDate             string    `json:"from_date,omitempty"`
+		ToDate               string    `json:"to_date,omitempty"`
+		GroupBy              []string  `json:"group_by,omitempty"`
+		Where                string    `json:"where,omitempty"`
 	}
 	// SummaryCalculator is a single thread implementation of the calculator. It keeps all state into its unexported
 	// structures. It MUST NOT be used in concurrent environments without proper synchronization. Besides that, all
@@ -23,74 +42,253 @@ type (
 		Filter
 		uniqueRecipesCache   map[string]int
 		busiestPostcode      map[string]int
+		dateCounts           map[string]int
+		groupCounts          map[string]int
+		recordFilter         recordFilter
 		postcodeAndTimeCount PostcodeAndTimeCount
 		nameMatchesCache     []string
+		nameMatchDetails     map[string]NameMatch
+		filterTerms          []filterTerm
+		filterTimeRange      filterTimeRange
+	}
+	// filterTimeRange is Filter.TimeRange parsed once by NewSummaryCalculator. from and to are the hours as written in
+	// the filter. E.g. "10AM - 3PM": from == "10AM", to == "3PM".
+	filterTimeRange struct {
+		DeliveryWindow
+		from, to string
+		err      error
+	}
+	// recordFilter selects the records aggregated by a Filter: the ones in its date range that match Filter.Where,
+	// parsed once. An invalid Filter.Where matches no record.
+	recordFilter struct {
+		from, to string
+		where    Expr
+		invalid  bool
+	}
+	// filterTerm pairs a Filter.Recipes term with the nameMatcher created for it.
+	filterTerm struct {
+		term string
+		nameMatcher
 	}
 )
 
+// Validate checks Filter.Postcode with ValidatePostcode, Filter.TimeRange with ValidateTimeRange, the date range with
+// ValidateDate, that the Filter.GroupBy fields are distinct, Filter.Where with ParseWhere and that every
+// Filter.Recipes term is valid for the Filter.MatchMode. For instance: an invalid regex.
+func (f Filter) Validate() error {
+	if err := ValidatePostcode(f.Postcode); err != nil {
+		return err
+	}
+	if err := ValidateTimeRange(f.TimeRange); err != nil {
+		return err
+	}
+	if err := ValidateDate("from date", f.FromDate); err != nil {
+		return err
+	}
+	if err := ValidateDate("to date", f.ToDate); err != nil {
+		return err
+	}
+	if f.MatchMode == FuzzyMatch && (f.Threshold <= 0 || f.Threshold > 1) {
+		return fmt.Errorf("invalid threshold %v, it must be greater than 0 and at most 1", f.Threshold)
+	}
+	if f.FromDate != "" && f.ToDate != "" && f.ToDate < f.FromDate {
+		return &FilterError{"to date", f.ToDate, 0, fmt.Sprintf("it must not be before the from date %q", f.FromDate)}
+	}
+	seen := make(map[string]bool, len(f.GroupBy))
+	for _, field := range f.GroupBy {
+		if field == "" || seen[field] {
+			return fmt.Errorf("invalid group by %q, its fields must be distinct and not empty",
+				strings.Join(f.GroupBy, ","))
+		}
+		seen[field] = true
+	}
+	if _, err := ParseWhere(f.Where); err != nil {
+		return err
+	}
+	_, err := f.filterTerms()
+
+	return err
+}
+
+// ValidateFields checks that every field of Filter.GroupBy and Filter.Where is a field of schema, so no group or
+// condition is evaluated with a field that is always empty. Filter.Where must be valid.
+func (f Filter) ValidateFields(schema Schema) error {
+	for _, field := range f.GroupBy {
+		if !schema.HasField(field) {
+			return fmt.Errorf("invalid group by field %q, it is neither a record field nor an attribute", field)
+		}
+	}
+
+	where, err := ParseWhere(f.Where)
+	if err != nil {
+		return err
+	}
+	whereFields(where, func(field string) {
+		if err == nil && !schema.HasField(field) {
+			err = fmt.Errorf("invalid where field %q, it is neither a record field nor an attribute", field)
+		}
+	})
+
+	return err
+}
+
+func newRecordFilter(f Filter) recordFilter {
+	where, err := ParseWhere(f.Where)
+
+	return recordFilter{f.FromDate, f.ToDate, where, err != nil}
+}
+
+// includes tells if r is aggregated. Without a date range every record is in it; with one, records without a valid
+// DeliveryDate are not. Validated dates compare as strings.
+func (f recordFilter) includes(r Record) bool {
+	if f.invalid {
+		return false
+	}
+	if f.from != "" || f.to != "" {
+		date, ok := r.deliveryDate()
+		if !ok || (f.from != "" && date < f.from) || (f.to != "" && date > f.to) {
+			return false
+		}
+	}
+
+	return f.where == nil || f.where.Match(r)
+}
+
+// filterTerms creates a nameMatcher for each valid term of Filter.Recipes. It returns the first error found.
+func (f Filter) filterTerms() ([]filterTerm, error) {
+	var (
+		terms    []filterTerm
+		firstErr error
+	)
+	for _, term := range f.Recipes {
+		m, err := newNameMatcher(f.MatchMode, term, f.Threshold)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		terms = append(terms, filterTerm{term, m})
+	}
+
+	return terms, firstErr
+}
+
 // NewSummaryCalculator creates SummaryCalculator given a filter. It is important use this function instead
-// creating a SummaryCalculator directly.
+// creating a SummaryCalculator directly. Invalid Filter.Recipes terms never match; use Filter.Validate to report them.
 func NewSummaryCalculator(filter Filter) SummaryCalculator {
-	return SummaryCalculator{filter, make(map[string]int), make(map[string]int),
-		PostcodeAndTimeCount{}, nil,
+	terms, _ := filter.filterTerms()
+
+	return SummaryCalculator{filter, make(map[string]int), make(map[string]int), make(map[string]int),
+		make(map[string]int), newRecordFilter(filter), PostcodeAndTimeCount{}, nil, make(map[string]NameMatch), terms,
+		newFilterTimeRange(filter.TimeRange),
 	}
 }
 
+func newFilterTimeRange(timeRange string) filterTimeRange {
+	w, err := parseTimeRange(timeRange)
+	if err != nil {
+		return filterTimeRange{err: err}
+	}
+
+	from, _ := GetBeginHourStr(timeRange)
+	to, _ := GetEndHourStr(timeRange)
+
+	return filterTimeRange{w, from[0], to[0], nil}
+}
+
 // Aggregate applies sorting uniqueRecipesCache and get the busiestPostcode applying a count sorting and getting
 // the postcode with more appearances in the input JSON file. If two postcode are tied with the same count, it gets the
 // one with lower number. E.g. 666 has 6 appearances as 10212 does, it will choose 666.
 func (s SummaryCalculator) Aggregate() Aggregation {
 	recipeCount := s.sumRecipes()
-	busiestPostcode := s.calcBusiestPostCode()
+	sortedPostcodes := s.sortPostcodes()
+	busiestPostcode := calcBusiestPostCode(sortedPostcodes)
+	nameMatchDetails, nameMatchesDeliveryCount := s.sumNameMatches()
+	if !s.Filter.PostcodeDistribution {
+		sortedPostcodes = nil
+	}
 	return Aggregation{
 		UniqueRecipeName:     len(s.uniqueRecipesCache),
 		RecipeCount:          recipeCount,
 		BusiestPostcode:      busiestPostcode,
 		PostcodeAndTimeCount: s.postcodeAndTimeCount,
 		NameMatches:          s.nameMatchesCache,
+		NameMatchDetails:     nameMatchDetails,
+		NameMatchesCount:     nameMatchesDeliveryCount,
+		PostcodeCount:        sortedPostcodes,
+		DateCount:            s.sumDates(),
+		GroupCount:           s.sumGroups(),
 	}
 }
 
-// Calculate adds Record information in its caches according functional requirements. It was designed to be used
-// in a single thread environment and using it in a concurrent environment might causes unpredictable behavior.
+// Calculate adds Record information in its caches according functional requirements. Records out of the date range
+// of the filter or that do not match its Filter.Where are skipped. It was designed to be used in a single thread
+// environment and using it in a concurrent environment might causes unpredictable behavior.
 func (s *SummaryCalculator) Calculate(r Record) {
+	if !s.recordFilter.includes(r) {
+		return
+	}
+
 	s.uniqueRecipesCache[r.Recipe]++
 	s.busiestPostcode[r.Postcode]++
+	if date, ok := r.deliveryDate(); ok {
+		s.dateCounts[date]++
+	}
+	if len(s.Filter.GroupBy) > 0 {
+		s.groupCounts[s.groupKey(r)]++
+	}
 	s.addToNamesMatches(r)
 	s.filterRecipeAccordingFilter(r)
 }
 
+// addToNamesMatches evaluates the filter terms only once per distinct recipe name. The result is memoized into
+// nameMatchDetails, including names that did not match (they have no Terms), so it also works as the set of already
+// inserted names of nameMatchesCache.
 func (s *SummaryCalculator) addToNamesMatches(r Record) {
-	for _, fr := range s.Filter.Recipes {
-		lowerFilterRecipe := strings.ToLower(fr)
-		lowerRecordRecipe := strings.ToLower(r.Recipe)
-
-		if strings.Contains(lowerRecordRecipe, lowerFilterRecipe) && !s.isNameAlreadyInserted(r.Recipe) {
+	nm, evaluated := s.nameMatchDetails[r.Recipe]
+	if !evaluated {
+		nm = s.matchName(r.Recipe)
+		if len(nm.Terms) > 0 {
 			s.insertSorted(r.Recipe)
 		}
 	}
+
+	if len(nm.Terms) > 0 {
+		nm.DeliveryCount++
+	}
+	s.nameMatchDetails[r.Recipe] = nm
+}
+
+func (s SummaryCalculator) matchName(recipe string) NameMatch {
+	nm := NameMatch{Recipe: recipe}
+	lowerRecipe := strings.ToLower(recipe)
+	for _, ft := range s.filterTerms {
+		score, ok := ft.match(recipe, lowerRecipe)
+		if !ok {
+			continue
+		}
+
+		nm.Terms = append(nm.Terms, ft.term)
+		if score > nm.Score {
+			nm.Score = score
+		}
+	}
+
+	return nm
 }
 
 // filterRecipeAccordingFilter does not take weekday in consideration to perform the query
 func (s *SummaryCalculator) filterRecipeAccordingFilter(r Record) {
-	if s.Filter.Postcode != r.Postcode || !r.DeliveredBetween(s.Filter.TimeRange) {
+	tr := s.filterTimeRange
+	if s.Filter.Postcode != r.Postcode || tr.err != nil || !r.deliveredBetween(tr.DeliveryWindow) {
 		return
 	}
 
 	s.postcodeAndTimeCount.DeliveryCount++
 	s.postcodeAndTimeCount.Postcode = r.Postcode
-
-	from, err := GetBeginHourStr(s.Filter.TimeRange)
-	if err != nil {
-		return
-	}
-	s.postcodeAndTimeCount.From = from[0]
-
-	to, err := GetEndHourStr(s.Filter.TimeRange)
-	if err != nil {
-		return
-	}
-	s.postcodeAndTimeCount.To = to[0]
+	s.postcodeAndTimeCount.From = tr.from
+	s.postcodeAndTimeCount.To = tr.to
 }
 
 func (s SummaryCalculator) sumRecipes() []RecipeCount {
@@ -113,43 +311,113 @@ func (s SummaryCalculator) sumRecipes() []RecipeCount {
 	return sortedRecipes
 }
 
-func (s SummaryCalculator) calcBusiestPostCode() BusiestPostcode {
-	var sortedBusiestPostCodes []BusiestPostcode
-	for k, v := range s.busiestPostcode {
-		sortedBusiestPostCodes = append(sortedBusiestPostCodes, BusiestPostcode{
+// sumDates returns the delivery count of each date sorted by date. It is nil if no record has a DeliveryDate.
+func (s SummaryCalculator) sumDates() []DateCount {
+	var dates []DateCount
+	for k, v := range s.dateCounts {
+		dates = append(dates, DateCount{Date: k, DeliveryCount: v})
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Date < dates[j].Date
+	})
+
+	return dates
+}
+
+// groupKey joins the values of the Filter.GroupBy fields of r with groupSeparator.
+func (s SummaryCalculator) groupKey(r Record) string {
+	if len(s.Filter.GroupBy) == 1 {
+		return r.Field(s.Filter.GroupBy[0])
+	}
+
+	var b strings.Builder
+	for i, field := range s.Filter.GroupBy {
+		if i > 0 {
+			b.WriteString(groupSeparator)
+		}
+		b.WriteString(r.Field(field))
+	}
+
+	return b.String()
+}
+
+// sumGroups returns the delivery count of each group sorted by most delivery count and then by its values.
+func (s SummaryCalculator) sumGroups() []GroupCount {
+	keys := make([]string, 0, len(s.groupCounts))
+	for k := range s.groupCounts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		iDC, jDC := s.groupCounts[keys[i]], s.groupCounts[keys[j]]
+
+		return (iDC == jDC && keys[i] < keys[j]) || iDC > jDC
+	})
+
+	var groups []GroupCount
+	for _, k := range keys {
+		values := strings.Split(k, groupSeparator)
+		group := make(map[string]string, len(values))
+		for i, field := range s.Filter.GroupBy {
+			group[field] = values[i]
+		}
+		groups = append(groups, GroupCount{Group: group, DeliveryCount: s.groupCounts[k]})
+	}
+
+	return groups
+}
+
+// sortPostcodes sorts the postcodes by most delivery count and then lower postcode.
+func (s SummaryCalculator) sortPostcodes() []PostcodeCount {
+	return sortPostcodeCounts(s.busiestPostcode)
+}
+
+// sortPostcodeCounts sorts the postcodes of counts by most delivery count and then lower postcode.
+func sortPostcodeCounts(counts map[string]int) []PostcodeCount {
+	var sortedPostcodes []PostcodeCount
+	for k, v := range counts {
+		sortedPostcodes = append(sortedPostcodes, PostcodeCount{
 			Postcode:      k,
 			DeliveryCount: v,
 		})
 	}
 
-	// Sort by most delivery count and then lower postcode
-	sort.Slice(sortedBusiestPostCodes, func(i, j int) bool {
-		iDC, jDC := sortedBusiestPostCodes[i].DeliveryCount, sortedBusiestPostCodes[j].DeliveryCount
-		iPC, jPC := sortedBusiestPostCodes[i].Postcode, sortedBusiestPostCodes[j].Postcode
+	sort.Slice(sortedPostcodes, func(i, j int) bool {
+		iDC, jDC := sortedPostcodes[i].DeliveryCount, sortedPostcodes[j].DeliveryCount
+		iPC, jPC := sortedPostcodes[i].Postcode, sortedPostcodes[j].Postcode
 
 		return (iDC == jDC && iPC < jPC) || iDC > jDC
 	})
 
-	if len(sortedBusiestPostCodes) > 0 {
-		return sortedBusiestPostCodes[0]
+	return sortedPostcodes
+}
+
+func calcBusiestPostCode(sortedPostcodes []PostcodeCount) BusiestPostcode {
+	if len(sortedPostcodes) > 0 {
+		return BusiestPostcode(sortedPostcodes[0])
 	}
 
 	return BusiestPostcode{}
 }
 
+// sumNameMatches returns the details of each name in nameMatchesCache keeping its order and the sum of their
+// delivery counts.
+func (s SummaryCalculator) sumNameMatches() ([]NameMatch, int) {
+	var (
+		details []NameMatch
+		total   int
+	)
+	for _, name := range s.nameMatchesCache {
+		nm := s.nameMatchDetails[name]
+		details = append(details, nm)
+		total += nm.DeliveryCount
+	}
+
+	return details, total
+}
 
 func (s *SummaryCalculator) insertSorted(name string) {
 	i := sort.SearchStrings(s.nameMatchesCache, name)
 	s.nameMatchesCache = append(s.nameMatchesCache, "")
 	copy(s.nameMatchesCache[i+1:], s.nameMatchesCache[i:])
 	s.nameMatchesCache[i] = name
-}
-
-func (s *SummaryCalculator) isNameAlreadyInserted(e string) bool {
-	for _, a := range s.nameMatchesCache {
-		if a == e {
-			return true
-		}
-	}
-	return false
 }
\ No newline at end of file
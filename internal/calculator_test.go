@@ -1,7 +1,10 @@
 package internal
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -19,6 +22,10 @@ func TestCalculate(t *testing.T) {
 		{"Not found postcode", happyPathRecords, notFoundPostcodeFilter, notFoundPostcodeAggregation},
 		{"Invalid range filter", happyPathRecords, invalidRangeFilter, invalidRangeAggregation},
 		{"Duplicated name matches", duplicatedNameMatchesRecords, regularFilter, duplicatedNameMatchesAggregation},
+		{"Fuzzy names", happyPathRecords, fuzzyNamesFilter, fuzzyNamesAggregation},
+		{"Invalid regex names", happyPathRecords, invalidRegexNamesFilter, notFoundNamesAggregation},
+		{"Multiple terms match", happyPathRecords, multipleTermsFilter, multipleTermsAggregation},
+		{"Postcode distribution", tiedPostcodesRecords, postcodeDistributionFilter, postcodeDistributionAggregation},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -36,11 +43,275 @@ func TestCalculate(t *testing.T) {
 	}
 }
 
+func TestCalculateDateRange(t *testing.T) {
+	records := []Record{
+		{Postcode: "10120", Recipe: "Creamy Dill Chicken", Delivery: "Monday 10AM - 2PM", DeliveryDate: "2026-09-30"},
+		{Postcode: "10120", Recipe: "Creamy Dill Chicken", Delivery: "Monday 10AM - 2PM", DeliveryDate: "2026-10-01"},
+		{Postcode: "10224", Recipe: "Tex-Mex Tilapia", Delivery: "Friday 8AM - 3PM",
+			DeliveryDate: "2026-10-07T08:00:00Z"},
+		{Postcode: "10224", Recipe: "Tex-Mex Tilapia", Delivery: "Friday 8AM - 3PM", DeliveryDate: "2026-10-08"},
+		{Postcode: "10224", Recipe: "Speedy Steak Fajitas", Delivery: "Friday 8AM - 3PM"},
+	}
+	cases := []struct {
+		name        string
+		from, to    string
+		wantRecipes []RecipeCount
+		wantDates   []DateCount
+	}{
+		{"Without range", "", "", []RecipeCount{{"Creamy Dill Chicken", 2}, {"Speedy Steak Fajitas", 1},
+			{"Tex-Mex Tilapia", 2}}, []DateCount{{"2026-09-30", 1}, {"2026-10-01", 1}, {"2026-10-07", 1},
+			{"2026-10-08", 1}}},
+		{"Inclusive range", "2026-10-01", "2026-10-07", []RecipeCount{{"Creamy Dill Chicken", 1},
+			{"Tex-Mex Tilapia", 1}}, []DateCount{{"2026-10-01", 1}, {"2026-10-07", 1}}},
+		{"Only from", "2026-10-07", "", []RecipeCount{{"Tex-Mex Tilapia", 2}},
+			[]DateCount{{"2026-10-07", 1}, {"2026-10-08", 1}}},
+		{"Only to", "", "2026-09-30", []RecipeCount{{"Creamy Dill Chicken", 1}}, []DateCount{{"2026-09-30", 1}}},
+		{"No record in range", "2026-11-01", "2026-11-30", nil, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filter := regularFilter
+			filter.FromDate, filter.ToDate = c.from, c.to
+			summaryCalculator := NewSummaryCalculator(filter)
+			for _, r := range records {
+				summaryCalculator.Calculate(r)
+			}
+			got := summaryCalculator.Aggregate()
+
+			if !reflect.DeepEqual(c.wantRecipes, got.RecipeCount) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantRecipes, got.RecipeCount)
+			}
+			if !reflect.DeepEqual(c.wantDates, got.DateCount) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantDates, got.DateCount)
+			}
+		})
+	}
+}
+
+func TestFilterValidateDateRange(t *testing.T) {
+	cases := []struct {
+		name     string
+		from, to string
+		want     error
+	}{
+		{"Range", "2026-10-01", "2026-10-07", nil},
+		{"Single day", "2026-10-01", "2026-10-01", nil},
+		{"Invalid from", "2026-13-01", "", &FilterError{"from date", "2026-13-01", 5, "month must be 1 to 12, got 13"}},
+		{"To before from", "2026-10-07", "2026-10-01", &FilterError{"to date", "2026-10-01", 0,
+			`it must not be before the from date "2026-10-07"`}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filter := regularFilter
+			filter.FromDate, filter.ToDate = c.from, c.to
+
+			if got := filter.Validate(); !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterValidateThreshold(t *testing.T) {
+	cases := []struct {
+		name      string
+		mode      MatchMode
+		threshold float64
+		wantErr   bool
+	}{
+		{"Default", FuzzyMatch, DefaultFuzzyThreshold, false},
+		{"One", FuzzyMatch, 1, false},
+		{"Zero", FuzzyMatch, 0, true},
+		{"Negative", FuzzyMatch, -0.1, true},
+		{"Above one", FuzzyMatch, 1.5, true},
+		{"Zero without fuzzy", SubstringMatch, 0, false},
+		{"Zero without mode", "", 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filter := regularFilter
+			filter.MatchMode, filter.Threshold = c.mode, c.threshold
+
+			if err := filter.Validate(); (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCalculateGroupBy(t *testing.T) {
+	records := []Record{
+		{Postcode: "10120", Recipe: "Creamy Dill Chicken", Attributes: map[string]string{"country": "DE", "box_size": "2"}},
+		{Postcode: "10120", Recipe: "Tex-Mex Tilapia", Attributes: map[string]string{"country": "DE", "box_size": "4"}},
+		{Postcode: "10224", Recipe: "Tex-Mex Tilapia", Attributes: map[string]string{"country": "NL", "box_size": "2"}},
+		{Postcode: "10224", Recipe: "Tex-Mex Tilapia", Attributes: map[string]string{"country": "DE", "box_size": "2"}},
+	}
+	cases := []struct {
+		name    string
+		groupBy []string
+		want    []GroupCount
+	}{
+		{"Without group by", nil, nil},
+		{"Record field", []string{"postcode"}, []GroupCount{{map[string]string{"postcode": "10120"}, 2},
+			{map[string]string{"postcode": "10224"}, 2}}},
+		{"Attributes", []string{"country", "box_size"}, []GroupCount{
+			{map[string]string{"country": "DE", "box_size": "2"}, 2},
+			{map[string]string{"country": "DE", "box_size": "4"}, 1},
+			{map[string]string{"country": "NL", "box_size": "2"}, 1}}},
+		{"Missing attribute", []string{"customer_type"}, []GroupCount{{map[string]string{"customer_type": ""}, 4}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filter := regularFilter
+			filter.GroupBy = c.groupBy
+			summaryCalculator := NewSummaryCalculator(filter)
+			for _, r := range records {
+				summaryCalculator.Calculate(r)
+			}
+
+			if got := summaryCalculator.Aggregate().GroupCount; !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterValidateFields(t *testing.T) {
+	schema := Schema{Attributes: []Attribute{{"country", "market.country"}}}
+	cases := []struct {
+		name    string
+		groupBy []string
+		wantErr bool
+	}{
+		{"Record fields and attributes", []string{"recipe", "delivery_date", "country"}, false},
+		{"Unknown field", []string{"box_size"}, true},
+		{"Repeated field", []string{"country", "country"}, true},
+		{"Empty field", []string{""}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filter := regularFilter
+			filter.GroupBy = c.groupBy
+			err := filter.Validate()
+			if err == nil {
+				err = filter.ValidateFields(schema)
+			}
+
+			if (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantErr, err)
+			}
+		})
+	}
+}
+
+// BenchmarkNameMatches compares the memoized name matching of SummaryCalculator against the former implementation,
+// which lowered the recipe for every term of every record and scanned the matches to deduplicate them.
+func BenchmarkNameMatches(b *testing.B) {
+	records := fixtureRecords(b, 100)
+	for _, n := range []int{3, 30, 300} {
+		terms := benchmarkTerms(n)
+		b.Run(fmt.Sprintf("naive/terms=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				nm := naiveNameMatches{terms: terms}
+				for _, r := range records {
+					nm.Calculate(r)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("memoized/terms=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			filter := Filter{Recipes: terms}
+			for i := 0; i < b.N; i++ {
+				s := NewSummaryCalculator(filter)
+				for _, r := range records {
+					s.addToNamesMatches(r)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCalculate(b *testing.B) {
+	records := fixtureRecords(b, 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := NewSummaryCalculator(regularFilter)
+		for _, r := range records {
+			s.Calculate(r)
+		}
+		s.Aggregate()
+	}
+}
+
+// fixtureRecords parses the hundred line sample and repeats its records the given number of times.
+func fixtureRecords(b *testing.B, times int) []Record {
+	mc := mockCalculator{}
+	Parse("../test/hundred_line_sample.json", &mc, false)
+	if len(mc.results) == 0 {
+		b.Fatal("no records parsed from fixture")
+	}
+
+	records := make([]Record, 0, len(mc.results)*times)
+	for i := 0; i < times; i++ {
+		records = append(records, mc.results...)
+	}
+
+	return records
+}
+
+// benchmarkTerms returns the default filter names followed by terms that never match until it has n terms.
+func benchmarkTerms(n int) []string {
+	terms := []string{"Potato", "Veggie", "Mushroom"}
+	for i := len(terms); i < n; i++ {
+		terms = append(terms, fmt.Sprintf("Unknown Recipe %d", i))
+	}
+
+	return terms
+}
+
+type naiveNameMatches struct {
+	terms   []string
+	matches []string
+}
+
+func (n *naiveNameMatches) Calculate(r Record) {
+	for _, fr := range n.terms {
+		if !strings.Contains(strings.ToLower(r.Recipe), strings.ToLower(fr)) {
+			continue
+		}
+
+		inserted := false
+		for _, m := range n.matches {
+			if m == r.Recipe {
+				inserted = true
+				break
+			}
+		}
+		if !inserted {
+			i := sort.SearchStrings(n.matches, r.Recipe)
+			n.matches = append(n.matches, "")
+			copy(n.matches[i+1:], n.matches[i:])
+			n.matches[i] = r.Recipe
+		}
+	}
+}
+
 var (
 	regularFilter = Filter{
 		Postcode:  "10120",
 		TimeRange: "10AM - 3PM",
 		Recipes:   []string{"Potato", "Veggie", "Mushroom"},
+		Threshold: DefaultFuzzyThreshold,
+	}
+	regularNameMatchDetails = []NameMatch{
+		{"American One-Pan Mushroom", 1, []string{"Mushroom"}, 1},
+		{"Grilled Cheese and Veggie Jumble", 1, []string{"Veggie"}, 1},
 	}
 	happyPathAggregation = Aggregation{
 		UniqueRecipeName: 9,
@@ -65,7 +336,9 @@ var (
 			To:            "3PM",
 			DeliveryCount: 1,
 		},
-		NameMatches: []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatches:      []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails: regularNameMatchDetails,
+		NameMatchesCount: 2,
 	}
 	happyPathRecords = []Record{
 		{
@@ -149,7 +422,9 @@ var (
 			To:            "3PM",
 			DeliveryCount: 1,
 		},
-		NameMatches: []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatches:      []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails: regularNameMatchDetails,
+		NameMatchesCount: 2,
 	}
 	tiedPostcodesRecords = []Record{
 		{
@@ -256,6 +531,8 @@ var (
 		},
 		PostcodeAndTimeCount: PostcodeAndTimeCount{},
 		NameMatches:          []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails:     regularNameMatchDetails,
+		NameMatchesCount:     2,
 	}
 	invalidRangeFilter = Filter{
 		Postcode:  "10120",
@@ -281,6 +558,8 @@ var (
 		},
 		PostcodeAndTimeCount: PostcodeAndTimeCount{},
 		NameMatches:          []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails:     regularNameMatchDetails,
+		NameMatchesCount:     2,
 	}
 	duplicatedNameMatchesRecords = []Record{
 		{
@@ -363,5 +642,131 @@ var (
 			DeliveryCount: 1,
 		},
 		NameMatches: []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails: []NameMatch{
+			{"American One-Pan Mushroom", 1, []string{"Mushroom"}, 2},
+			{"Grilled Cheese and Veggie Jumble", 1, []string{"Veggie"}, 1},
+		},
+		NameMatchesCount: 3,
+	}
+	fuzzyNamesFilter = Filter{
+		Postcode:  "10120",
+		TimeRange: "10AM - 3PM",
+		Recipes:   []string{"Mushrom", "veggies jumble"},
+		MatchMode: FuzzyMatch,
+		Threshold: DefaultFuzzyThreshold,
+	}
+	fuzzyNamesAggregation = Aggregation{
+		UniqueRecipeName: 9,
+		RecipeCount: []RecipeCount{
+			{"American One-Pan Mushroom", 1},
+			{"Cherry Balsamic Pork Chops", 2},
+			{"Chicken Sausage Pizzas", 1},
+			{"Creamy Dill Chicken", 1},
+			{"Grilled Cheese and Veggie Jumble", 1},
+			{"Hot Honey Barbecue Chicken Legs", 1},
+			{"One-Pan Orzo Italiano", 1},
+			{"Speedy Steak Fajitas", 1},
+			{"Tex-Mex Tilapia", 1},
+		},
+		BusiestPostcode: BusiestPostcode{
+			"10224",
+			2,
+		},
+		PostcodeAndTimeCount: PostcodeAndTimeCount{
+			Postcode:      "10120",
+			From:          "10AM",
+			To:            "3PM",
+			DeliveryCount: 1,
+		},
+		NameMatches: []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails: []NameMatch{
+			{"American One-Pan Mushroom", 0.88, []string{"Mushrom"}, 1},
+			{"Grilled Cheese and Veggie Jumble", 0.93, []string{"veggies jumble"}, 1},
+		},
+		NameMatchesCount: 2,
+	}
+	invalidRegexNamesFilter = Filter{
+		Postcode:  "10120",
+		TimeRange: "10AM - 3PM",
+		Recipes:   []string{"Mush(room"},
+		MatchMode: RegexMatch,
+	}
+	multipleTermsFilter = Filter{
+		Postcode:  "10120",
+		TimeRange: "10AM - 3PM",
+		Recipes:   []string{"Veggie", "Jumble", "Chops"},
+	}
+	multipleTermsAggregation = Aggregation{
+		UniqueRecipeName: 9,
+		RecipeCount: []RecipeCount{
+			{"American One-Pan Mushroom", 1},
+			{"Cherry Balsamic Pork Chops", 2},
+			{"Chicken Sausage Pizzas", 1},
+			{"Creamy Dill Chicken", 1},
+			{"Grilled Cheese and Veggie Jumble", 1},
+			{"Hot Honey Barbecue Chicken Legs", 1},
+			{"One-Pan Orzo Italiano", 1},
+			{"Speedy Steak Fajitas", 1},
+			{"Tex-Mex Tilapia", 1},
+		},
+		BusiestPostcode: BusiestPostcode{
+			"10224",
+			2,
+		},
+		PostcodeAndTimeCount: PostcodeAndTimeCount{
+			Postcode:      "10120",
+			From:          "10AM",
+			To:            "3PM",
+			DeliveryCount: 1,
+		},
+		NameMatches: []string{"Cherry Balsamic Pork Chops", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails: []NameMatch{
+			{"Cherry Balsamic Pork Chops", 1, []string{"Chops"}, 2},
+			{"Grilled Cheese and Veggie Jumble", 1, []string{"Veggie", "Jumble"}, 1},
+		},
+		NameMatchesCount: 3,
+	}
+	postcodeDistributionFilter = Filter{
+		Postcode:             "10120",
+		TimeRange:            "10AM - 3PM",
+		Recipes:              []string{"Potato", "Veggie", "Mushroom"},
+		PostcodeDistribution: true,
+	}
+	postcodeDistributionAggregation = Aggregation{
+		UniqueRecipeName: 9,
+		RecipeCount: []RecipeCount{
+			{"American One-Pan Mushroom", 1},
+			{"Cherry Balsamic Pork Chops", 2},
+			{"Chicken Sausage Pizzas", 1},
+			{"Creamy Dill Chicken", 1},
+			{"Grilled Cheese and Veggie Jumble", 1},
+			{"Hot Honey Barbecue Chicken Legs", 1},
+			{"One-Pan Orzo Italiano", 1},
+			{"Speedy Steak Fajitas", 1},
+			{"Tex-Mex Tilapia", 1},
+		},
+		BusiestPostcode: BusiestPostcode{
+			"10224",
+			2,
+		},
+		PostcodeAndTimeCount: PostcodeAndTimeCount{
+			Postcode:      "10120",
+			From:          "10AM",
+			To:            "3PM",
+			DeliveryCount: 1,
+		},
+		NameMatches:      []string{"American One-Pan Mushroom", "Grilled Cheese and Veggie Jumble"},
+		NameMatchDetails: regularNameMatchDetails,
+		NameMatchesCount: 2,
+		PostcodeCount: []PostcodeCount{
+			{"10224", 2},
+			{"999999", 2},
+			{"10120", 1},
+			{"10127", 1},
+			{"10148", 1},
+			{"10163", 1},
+			{"10180", 1},
+			{"10186", 1},
+		},
 	}
 )
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// CheckpointVersion is the version of the Checkpoint format. LoadCheckpoint rejects checkpoints of other versions.
+	CheckpointVersion = 1
+	// DefaultCheckpointInterval is how many records a CheckpointParser parses between checkpoints.
+	DefaultCheckpointInterval = 100000
+)
+
+type (
+	// Checkpoint is the position reached by a CheckpointParser in a file and the state of its SummaryCalculator at that
+	// position. Offset is the byte right after the record. Records, Parsed and Ignored count the records of the file
+	// up to it and Array tells if the file is a JSON array. Size is the size of the file, so a file changed since the
+	// checkpoint is not resumed.
+	Checkpoint struct {
+		Version int             `json:"version"`
+		File    string          `json:"file"`
+		Size    int64           `json:"size"`
+		Offset  int64           `json:"offset"`
+		Records int             `json:"records"`
+		Array   bool            `json:"array"`
+		Parsed  int             `json:"parsed"`
+		Ignored int             `json:"ignored"`
+		State   CalculatorState `json:"state"`
+	}
+	// CheckpointParser parses a file as Parse does and saves a Checkpoint to Path every Interval records. Calc
+	// calculates the records with Calculator, whose state is saved, either directly or wrapped by another Calculator
+	// such as NormalizingCalculator. Schema decodes the records; the zero Schema is DefaultSchema. Recipes is the
+	// RecipeNormalizer of Calc, if any, whose names are saved in the state.
+	CheckpointParser struct {
+		Path       string
+		Interval   int
+		Calculator *SummaryCalculator
+		Calc       Calculator
+		Schema     Schema
+		Recipes    *RecipeNormalizer
+	}
+)
+
+// Parse parses file from its start or, if from is not nil, from the record after the checkpoint. In that case the
+// Calculator must be restored from Checkpoint.State first, and Recipes from its RecipeNames. The counts returned
+// include the ones of from. Once ctx is done, it saves a checkpoint at the last record calculated and returns
+// ctx.Err(), so a resumed run goes on from there.
+// It returns an error if the file cannot be read or parsed, if it changed since from or if a checkpoint cannot be
+// saved.
+func (p CheckpointParser) Parse(ctx context.Context, file string, from *Checkpoint, isVerbose bool) (parsed int,
+	ignored int, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error to read [file=%v]: %v", file, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error to read [file=%v]: %v", file, err)
+	}
+
+	var (
+		pos  *recordPosition
+		last recordPosition
+	)
+	if from != nil {
+		if from.File != file || from.Size != info.Size() {
+			return 0, 0, fmt.Errorf("file changed since the checkpoint [file=%v]", file)
+		}
+		if _, err := f.Seek(from.Offset, io.SeekStart); err != nil {
+			return 0, 0, fmt.Errorf("error to read [file=%v]: %v", file, err)
+		}
+		pos = &recordPosition{from.Offset, from.Records, from.Array}
+		last = *pos
+		parsed, ignored = from.Parsed, from.Ignored
+	}
+
+	save := func(rp recordPosition) error {
+		st := p.Calculator.State()
+		if p.Recipes != nil {
+			st.RecipeNames = p.Recipes.Names()
+		}
+		return SaveCheckpoint(p.Path, Checkpoint{
+			Version: CheckpointVersion,
+			File:    file,
+			Size:    info.Size(),
+			Offset:  rp.Offset,
+			Records: rp.Index,
+			Array:   rp.Array,
+			Parsed:  parsed,
+			Ignored: ignored,
+			State:   st,
+		})
+	}
+	err = decodeRecordsFrom(f, p.Schema, pos, func(rp recordPosition, r *Record) error {
+		if err := ctx.Err(); err != nil {
+			if last.Index > 0 {
+				if err := save(last); err != nil {
+					return err
+				}
+			}
+			return err
+		}
+
+		if r.IsValid() {
+			p.Calc.Calculate(*r)
+			parsed++
+		} else {
+			ignored++
+		}
+		logCount(isVerbose, rp.Index, parsed, ignored)
+		last = rp
+
+		if p.Interval <= 0 || rp.Index%p.Interval != 0 {
+			return nil
+		}
+		return save(rp)
+	})
+
+	return parsed, ignored, err
+}
+
+// LoadCheckpoint reads a Checkpoint saved by SaveCheckpoint.
+// It returns an error if the file cannot be read or decoded or if the checkpoint has another version.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("error to read checkpoint [file=%v]: %v", path, err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(content, &cp); err != nil {
+		return Checkpoint{}, fmt.Errorf("error to decode checkpoint [file=%v]: %v", path, err)
+	}
+	if cp.Version != CheckpointVersion {
+		return Checkpoint{}, fmt.Errorf("unsupported checkpoint version %d, want %d [file=%v]", cp.Version,
+			CheckpointVersion, path)
+	}
+
+	return cp, nil
+}
+
+// SaveCheckpoint writes cp as JSON to path. Like SaveState, path keeps the previous checkpoint if the process stops
+// while writing.
+// It returns an error if the file cannot be written.
+func SaveCheckpoint(path string, cp Checkpoint) error {
+	return saveJSON(path, "checkpoint", cp)
+}
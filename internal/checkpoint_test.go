@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const checkpointFile = "tf.checkpoint"
+
+// errInterrupted stops a CheckpointParser as if the process was killed.
+type errInterrupted struct{}
+
+// interruptingCalculator panics with errInterrupted after calculating n records.
+type interruptingCalculator struct {
+	Calculator
+	n int
+}
+
+func (c *interruptingCalculator) Calculate(r Record) {
+	if c.n == 0 {
+		panic(errInterrupted{})
+	}
+	c.n--
+	c.Calculator.Calculate(r)
+}
+
+func TestCheckpointParserResume(t *testing.T) {
+	sample, err := os.ReadFile(sampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{"Array", string(sample)},
+		{"NDJSON", ndjson(t, sample)},
+		{"Array with invalid records", fixture},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			createFile(c.content)
+			defer removeFile()
+			defer os.Remove(checkpointFile)
+
+			full := NewSummaryCalculator(regularFilter)
+			parser := CheckpointParser{Calculator: &full, Calc: &full}
+			parsedWant, ignoredWant, err := parser.Parse(context.Background(), stubFile, nil, false)
+			if err != nil {
+				t.Fatalf("%s, want: %v, got: %v", c.name, nil, err)
+			}
+			want := full.Aggregate()
+
+			for interruptAt := 0; interruptAt < parsedWant; interruptAt++ {
+				os.Remove(checkpointFile)
+				interrupted := NewSummaryCalculator(regularFilter)
+				parseInterrupted(t, CheckpointParser{checkpointFile, 2, &interrupted,
+					&interruptingCalculator{&interrupted, interruptAt}, DefaultSchema, nil})
+
+				var from *Checkpoint
+				resumed := NewSummaryCalculator(regularFilter)
+				if cp, err := LoadCheckpoint(checkpointFile); err == nil {
+					from = &cp
+					if resumed, err = RestoreSummaryCalculator(regularFilter, cp.State); err != nil {
+						t.Fatalf("%s, want: %v, got: %v", c.name, nil, err)
+					}
+				}
+				parser = CheckpointParser{checkpointFile, 2, &resumed, &resumed, DefaultSchema, nil}
+				parsed, ignored, err := parser.Parse(context.Background(), stubFile, from, false)
+
+				if err != nil || parsed != parsedWant || ignored != ignoredWant {
+					t.Errorf("%s interrupted at %d, want: %v %v %v, got: %v %v %v", c.name, interruptAt, nil,
+						parsedWant, ignoredWant, err, parsed, ignored)
+				}
+				if got := resumed.Aggregate(); !reflect.DeepEqual(want, got) {
+					t.Errorf("%s interrupted at %d, want: %v, got: %v", c.name, interruptAt, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckpointParserCancel(t *testing.T) {
+	full := NewSummaryCalculator(regularFilter)
+	Parse(sampleFile, &full, false)
+	defer os.Remove(checkpointFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := NewSummaryCalculator(regularFilter)
+	parser := CheckpointParser{checkpointFile, DefaultCheckpointInterval, &cancelled,
+		&cancellingCalculator{&cancelled, 5, cancel}, DefaultSchema, nil}
+	parsed, _, err := parser.Parse(ctx, sampleFile, nil, false)
+	if err != context.Canceled || parsed != 5 {
+		t.Fatalf("Cancel, want: %v %v, got: %v %v", context.Canceled, 5, err, parsed)
+	}
+
+	cp, err := LoadCheckpoint(checkpointFile)
+	if err != nil || cp.Records != 5 {
+		t.Fatalf("LoadCheckpoint, want: %v %v, got: %v %v", nil, 5, err, cp.Records)
+	}
+	resumed, err := RestoreSummaryCalculator(regularFilter, cp.State)
+	if err != nil {
+		t.Fatalf("RestoreSummaryCalculator, want: %v, got: %v", nil, err)
+	}
+	parser = CheckpointParser{checkpointFile, DefaultCheckpointInterval, &resumed, &resumed, DefaultSchema, nil}
+	if _, _, err := parser.Parse(context.Background(), sampleFile, &cp, false); err != nil {
+		t.Fatalf("Resume, want: %v, got: %v", nil, err)
+	}
+
+	if want, got := full.Aggregate(), resumed.Aggregate(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Resume, want: %v, got: %v", want, got)
+	}
+}
+
+// TestCheckpointParserRecipeNames checks that a resumed parse spells the recipes as the interrupted one did.
+func TestCheckpointParserRecipeNames(t *testing.T) {
+	createFile(`{"postcode": "10120", "recipe": "Tex-Mex Tilapia", "delivery": "Wednesday 1AM - 7PM"}
+{"postcode": "10120", "recipe": "tex-mex  tilapia", "delivery": "Wednesday 1AM - 7PM"}
+`)
+	defer removeFile()
+	defer os.Remove(checkpointFile)
+
+	full := NewSummaryCalculator(regularFilter)
+	Parse(stubFile, NewNormalizingCalculator(&full, NewRecipeNormalizer(nil), nil), false)
+
+	interrupted, recipes := NewSummaryCalculator(regularFilter), NewRecipeNormalizer(nil)
+	parseInterrupted(t, CheckpointParser{checkpointFile, 1, &interrupted,
+		&interruptingCalculator{NewNormalizingCalculator(&interrupted, recipes, nil), 1}, DefaultSchema, recipes})
+
+	cp, err := LoadCheckpoint(checkpointFile)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint, want: %v, got: %v", nil, err)
+	}
+	resumed, err := RestoreSummaryCalculator(regularFilter, cp.State)
+	if err != nil {
+		t.Fatalf("RestoreSummaryCalculator, want: %v, got: %v", nil, err)
+	}
+	recipes = NewRecipeNormalizer(nil)
+	recipes.AddNames(cp.State.RecipeNames)
+	parser := CheckpointParser{checkpointFile, 1, &resumed, NewNormalizingCalculator(&resumed, recipes, nil),
+		DefaultSchema, recipes}
+	if _, _, err := parser.Parse(context.Background(), stubFile, &cp, false); err != nil {
+		t.Fatalf("Resume, want: %v, got: %v", nil, err)
+	}
+
+	if want, got := full.Aggregate(), resumed.Aggregate(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Resume, want: %v, got: %v", want, got)
+	}
+}
+
+func TestCheckpointParserChangedFile(t *testing.T) {
+	createFile(fixture)
+	defer removeFile()
+	calc := NewSummaryCalculator(regularFilter)
+	from := &Checkpoint{Version: CheckpointVersion, File: stubFile, Size: int64(len(fixture)) + 1}
+
+	_, _, err := CheckpointParser{Calculator: &calc, Calc: &calc}.Parse(context.Background(), stubFile, from, false)
+
+	if err == nil {
+		t.Errorf("Changed file, want: an error, got: %v", err)
+	}
+}
+
+func parseInterrupted(t *testing.T, p CheckpointParser) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(errInterrupted); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	if _, _, err := p.Parse(context.Background(), stubFile, nil, false); err != nil {
+		t.Fatalf("Parse, want: %v, got: %v", nil, err)
+	}
+}
+
+// ndjson converts a JSON array of records to NDJSON.
+func ndjson(t *testing.T, array []byte) string {
+	var records []json.RawMessage
+	if err := json.Unmarshal(array, &records); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := make([]string, len(records))
+	for i, r := range records {
+		lines[i] = strings.Join(strings.Fields(string(r)), " ")
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
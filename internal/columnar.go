@@ -0,0 +1,288 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+)
+
+// Layout of a columnar file. The header is columnarMagic, whose last byte is the version, the CRC-32 (IEEE) of the
+// payload and the length of the payload, both big endian. The payload is made of uvarints:
+//   - the number of records and the number of invalid records of the input;
+//   - the dictionaries of recipes, postcodes, deliveries, dates, weekdays and attribute values, each one its length
+//     followed by its strings, a length and the bytes, without the "" of id 0;
+//   - the packed delivery window of each delivery, as in Dataset;
+//   - the names of the attributes, as a dictionary;
+//   - the ids of the records in blocks of columnarBlockSize rows. Each block has the ids of every recipe, then of
+//     every postcode, delivery, date and attribute of its rows.
+const (
+	columnarMagic      = "RCCOL\x01"
+	columnarHeaderSize = len(columnarMagic) + 4 + 8
+	columnarBlockSize  = 4096
+)
+
+var errColumnarRecords = errors.New("the input is a columnar file, which has no JSON records")
+
+type (
+	// columnarEncoder encodes the payload of a columnar file.
+	columnarEncoder struct {
+		bytes.Buffer
+		scratch [binary.MaxVarintLen64]byte
+	}
+	// columnarDecoder decodes the payload of a columnar file. The first error is kept in err and stops the decoding.
+	columnarDecoder struct {
+		r   *bytes.Reader
+		err error
+	}
+)
+
+// WriteColumnar writes d in the columnar format that Parse reads: a compact binary file with the strings
+// dictionary-encoded and the delivery windows already parsed, so it is read without decoding JSON. ignored is the
+// number of invalid records of the input of d, so Parse returns the same counts as for the input.
+// It returns an error if w cannot be written.
+func (d *Dataset) WriteColumnar(w io.Writer, ignored int) error {
+	var e columnarEncoder
+	e.uvarint(uint64(d.Len()))
+	e.uvarint(uint64(ignored))
+	for _, sd := range []stringDict{d.recipes, d.postcodes, d.deliveries, d.dates, d.weekdays, d.values} {
+		e.strings(sd.values[1:])
+	}
+	for _, window := range d.windows[1:] {
+		e.uvarint(uint64(window))
+	}
+	e.strings(d.attrNames)
+
+	columns := append([][]uint32{d.recipe, d.postcode, d.delivery, d.date}, d.attrs...)
+	for start := 0; start < d.Len(); start += columnarBlockSize {
+		end := start + columnarBlockSize
+		if end > d.Len() {
+			end = d.Len()
+		}
+		for _, column := range columns {
+			for _, id := range column[start:end] {
+				e.uvarint(uint64(id))
+			}
+		}
+	}
+
+	header := make([]byte, columnarHeaderSize)
+	copy(header, columnarMagic)
+	binary.BigEndian.PutUint32(header[len(columnarMagic):], crc32.ChecksumIEEE(e.Bytes()))
+	binary.BigEndian.PutUint64(header[len(columnarMagic)+4:], uint64(e.Len()))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(e.Bytes())
+
+	return err
+}
+
+func (e *columnarEncoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.scratch[:], v)
+	e.Write(e.scratch[:n])
+}
+
+func (e *columnarEncoder) strings(values []string) {
+	e.uvarint(uint64(len(values)))
+	for _, s := range values {
+		e.uvarint(uint64(len(s)))
+		e.WriteString(s)
+	}
+}
+
+// isColumnar checks if the input of r starts with the header of a columnar file of any version. The input is left
+// in r.
+func isColumnar(r *bufio.Reader) bool {
+	magic, _ := r.Peek(len(columnarMagic) - 1)
+
+	return string(magic) == columnarMagic[:len(columnarMagic)-1]
+}
+
+// decodeColumnar is parseRecords for a columnar file written by Dataset.WriteColumnar. Its records were decoded with
+// the schema of the conversion, so schema only selects the attributes kept by their names; an attribute missing in
+// the file is "". The payload is checked against the checksum of the header before calculating any record.
+// It returns an error if the file is truncated, corrupted or of another version.
+func decodeColumnar(ctx context.Context, r io.Reader, schema Schema, calc Calculator,
+	progress func(rc, pc, ic int)) (parsed int, ignored int, err error) {
+	header := make([]byte, columnarHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, fmt.Errorf("error to read the columnar header: %v", err)
+	}
+	if string(header[:len(columnarMagic)]) != columnarMagic {
+		return 0, 0, fmt.Errorf("unsupported columnar file version %d, want %d", header[len(columnarMagic)-1],
+			columnarMagic[len(columnarMagic)-1])
+	}
+	checksum := binary.BigEndian.Uint32(header[len(columnarMagic):])
+	size := binary.BigEndian.Uint64(header[len(columnarMagic)+4:])
+
+	payload, err := ioutil.ReadAll(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return 0, 0, err
+	}
+	if uint64(len(payload)) != size {
+		return 0, 0, fmt.Errorf("truncated columnar file, want %d bytes, got %d", size, len(payload))
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return 0, 0, errors.New("corrupted columnar file, the checksum does not match")
+	}
+
+	d := &columnarDecoder{r: bytes.NewReader(payload)}
+	records, invalid := d.uvarint(), int(d.uvarint())
+	var dicts [6][]string
+	for i := range dicts {
+		dicts[i] = append([]string{""}, d.strings()...)
+	}
+	recipes, postcodes, deliveries, dates, weekdays, values := dicts[0], dicts[1], dicts[2], dicts[3], dicts[4],
+		dicts[5]
+	windows := make([]DeliveryWindow, len(deliveries))
+	for i := 1; i < len(windows); i++ {
+		windows[i] = d.window(weekdays)
+	}
+	attrNames := d.strings()
+	if d.err != nil {
+		return 0, 0, d.err
+	}
+	if records > uint64(d.r.Len()) {
+		return 0, 0, fmt.Errorf("corrupted columnar file, %d records in %d bytes", records, d.r.Len())
+	}
+
+	// attrs has the column of each attribute of schema, or -1 if the file does not have it.
+	attrs := make([]int, len(schema.Attributes))
+	for i, a := range schema.Attributes {
+		attrs[i] = -1
+		for j, name := range attrNames {
+			if name == a.Name {
+				attrs[i] = j
+			}
+		}
+	}
+
+	columnDicts := append([][]string{recipes, postcodes, deliveries, dates}, make([][]string, len(attrNames))...)
+	for i := range attrNames {
+		columnDicts[4+i] = values
+	}
+	columns := make([][]uint32, len(columnDicts))
+	for start := 0; start < int(records); start += columnarBlockSize {
+		n := int(records) - start
+		if n > columnarBlockSize {
+			n = columnarBlockSize
+		}
+		for c := range columns {
+			columns[c] = columns[c][:0]
+			for i := 0; i < n; i++ {
+				columns[c] = append(columns[c], d.id(len(columnDicts[c])))
+			}
+		}
+		if d.err != nil {
+			return parsed, ignored, d.err
+		}
+
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return parsed, ignored, err
+			}
+
+			rec := Record{
+				Recipe:       recipes[columns[0][i]],
+				Postcode:     postcodes[columns[1][i]],
+				Delivery:     deliveries[columns[2][i]],
+				DeliveryDate: dates[columns[3][i]],
+				window:       windows[columns[2][i]],
+			}
+			if len(attrs) > 0 {
+				rec.Attributes = make(map[string]string, len(attrs))
+				for j, c := range attrs {
+					rec.Attributes[schema.Attributes[j].Name] = ""
+					if c >= 0 {
+						rec.Attributes[schema.Attributes[j].Name] = values[columns[4+c][i]]
+					}
+				}
+			}
+			calc.Calculate(rec)
+			parsed++
+			progress(start+i+1, parsed, ignored)
+		}
+	}
+	if d.r.Len() > 0 {
+		return parsed, ignored, fmt.Errorf("corrupted columnar file, %d bytes after the records", d.r.Len())
+	}
+
+	ignored = invalid
+	progress(parsed+ignored, parsed, ignored)
+
+	return parsed, ignored, nil
+}
+
+func (d *columnarDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.err = fmt.Errorf("truncated columnar file: %v", err)
+	}
+
+	return v
+}
+
+// id decodes an id of a dictionary of n values.
+func (d *columnarDecoder) id(n int) uint32 {
+	id := d.uvarint()
+	if d.err == nil && id >= uint64(n) {
+		d.err = fmt.Errorf("corrupted columnar file, id %d out of a dictionary of %d values", id, n)
+		return 0
+	}
+
+	return uint32(id)
+}
+
+func (d *columnarDecoder) strings() []string {
+	n := d.uvarint()
+	if d.err == nil && n > uint64(d.r.Len()) {
+		d.err = fmt.Errorf("corrupted columnar file, %d strings in %d bytes", n, d.r.Len())
+	}
+	if d.err != nil {
+		return nil
+	}
+
+	values := make([]string, n)
+	for i := range values {
+		size := d.uvarint()
+		if d.err == nil && size > uint64(d.r.Len()) {
+			d.err = fmt.Errorf("corrupted columnar file, string of %d bytes in %d bytes", size, d.r.Len())
+		}
+		if d.err != nil {
+			return nil
+		}
+
+		b := make([]byte, size)
+		d.r.Read(b)
+		values[i] = string(b)
+	}
+
+	return values
+}
+
+// window decodes a packed delivery window whose weekday is an id of weekdays.
+func (d *columnarDecoder) window(weekdays []string) DeliveryWindow {
+	packed := d.uvarint()
+	if packed == 0 || d.err != nil {
+		return DeliveryWindow{}
+	}
+
+	weekday := packed >> (2 * windowHourBits)
+	if weekday >= uint64(len(weekdays)) {
+		d.err = fmt.Errorf("corrupted columnar file, weekday %d out of %d weekdays", weekday, len(weekdays))
+		return DeliveryWindow{}
+	}
+
+	return DeliveryWindow{weekdays[weekday], int(packed >> windowHourBits & windowHourMask),
+		int(packed & windowHourMask)}
+}
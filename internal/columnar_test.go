@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const columnarFile = "tf.rcc"
+
+// writeColumnarFile converts files, decoded with schema, into columnarFile.
+func writeColumnarFile(t *testing.T, files []string, schema Schema) {
+	d, _, ignored, err := LoadDataset(context.Background(), files, schema, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := d.WriteColumnar(&b, ignored); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(columnarFile, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestColumnarRoundTrip(t *testing.T) {
+	createFile(datasetFixture)
+	defer removeFile()
+	defer os.Remove(columnarFile)
+	boxSize := Schema{Attributes: []Attribute{{"box_size", "box_size"}}}
+	withMissing := Schema{Attributes: []Attribute{{"box_size", "box_size"}, {"country", "country"}}}
+	// blocks has the 20 records of sampleFile repeated over more than two blocks.
+	var blocks []string
+	for i := 0; i < columnarBlockSize/10+1; i++ {
+		blocks = append(blocks, sampleFile)
+	}
+
+	cases := []struct {
+		name          string
+		files         []string
+		convertSchema Schema
+		readSchema    Schema
+	}{
+		{"Sample", []string{sampleFile}, DefaultSchema, DefaultSchema},
+		{"Dates, attributes and invalid records", []string{stubFile}, boxSize, boxSize},
+		{"Files", []string{sampleFile, stubFile}, boxSize, boxSize},
+		{"Attributes not read", []string{stubFile}, boxSize, DefaultSchema},
+		{"Attribute not converted", []string{stubFile}, boxSize, withMissing},
+		{"Blocks", blocks, DefaultSchema, DefaultSchema},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var want mockCalculator
+			var wantParsed, wantIgnored int
+			for _, file := range c.files {
+				p, i, err := ParseContext(context.Background(), file, c.readSchema, &want, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wantParsed, wantIgnored = wantParsed+p, wantIgnored+i
+			}
+			writeColumnarFile(t, c.files, c.convertSchema)
+
+			var got mockCalculator
+			parsed, ignored, err := ParseContext(context.Background(), columnarFile, c.readSchema, &got, false)
+
+			if err != nil || wantParsed != parsed || wantIgnored != ignored {
+				t.Fatalf("%s, want: %v %v %v, got: %v %v %v", c.name, wantParsed, wantIgnored, nil, parsed, ignored,
+					err)
+			}
+			if !reflect.DeepEqual(want.results, got.results) {
+				t.Errorf("%s, want: %v, got: %v", c.name, want.results, got.results)
+			}
+		})
+	}
+}
+
+func TestColumnarErrors(t *testing.T) {
+	defer os.Remove(columnarFile)
+	writeColumnarFile(t, []string{sampleFile}, DefaultSchema)
+	content, err := os.ReadFile(columnarFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change := func(fn func(b []byte) []byte) []byte {
+		return fn(append([]byte(nil), content...))
+	}
+
+	cases := []struct {
+		name    string
+		content []byte
+	}{
+		{"Corrupted", change(func(b []byte) []byte { b[len(b)-1]++; return b })},
+		{"Truncated", content[:len(content)-1]},
+		{"Header only", content[:columnarHeaderSize-1]},
+		{"Other version", change(func(b []byte) []byte { b[len(columnarMagic)-1]++; return b })},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var mc mockCalculator
+			parsed, _, err := ParseReader(bytes.NewReader(c.content), &mc, false)
+
+			if err == nil || parsed != 0 || len(mc.results) != 0 {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, "error", 0, err, parsed)
+			}
+		})
+	}
+
+	if _, err := Validate(bytes.NewReader(content), DefaultSchema, -1); err != errColumnarRecords {
+		t.Errorf("Validate, want: %v, got: %v", errColumnarRecords, err)
+	}
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvPrefix is the prefix of the environment variables that set CLI options. See EnvName.
+const EnvPrefix = "RECIPE_AGGREGATOR_"
+
+// Config holds CLI option values read from a YAML file. Values apply to every command and Sections, named after a
+// command, override them for that command. E.g.:
+//
+//	postcode: "10120"
+//	names: [Potato, Veggie]
+//	serve:
+//	  addr: ":9090"
+//
+// Scalars are kept as they are written and lists are joined by commas, as in the --names flag.
+type Config struct {
+	Values   map[string]string
+	Sections map[string]map[string]string
+}
+
+// DefaultConfigPath returns $XDG_CONFIG_HOME/recipe-aggregator/config.yaml, or $HOME/.config/recipe-aggregator/
+// config.yaml if XDG_CONFIG_HOME is not set. It returns "" if neither is set.
+func DefaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "recipe-aggregator", "config.yaml")
+}
+
+// LoadConfig reads a YAML config file.
+// It returns an error if the file cannot be read or decoded, or if a value is neither a scalar, a list of scalars
+// nor a section.
+func LoadConfig(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("error to read config [file=%v]: %v", path, err)
+	}
+
+	var raw map[string]configEntry
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return Config{}, fmt.Errorf("error to decode config [file=%v]: %v", path, err)
+	}
+
+	cfg := Config{Values: make(map[string]string), Sections: make(map[string]map[string]string)}
+	for key, e := range raw {
+		if e.section == nil {
+			cfg.Values[key] = string(e.value)
+			continue
+		}
+
+		cfg.Sections[key] = make(map[string]string, len(e.section))
+		for name, v := range e.section {
+			cfg.Sections[key][name] = string(v)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Value returns the value of the option name of command.
+func (c Config) Value(command, name string) (string, bool) {
+	if v, ok := c.Sections[command][name]; ok {
+		return v, true
+	}
+	v, ok := c.Values[name]
+
+	return v, ok
+}
+
+// EnvName returns the environment variable of the option name. E.g. "match-mode": "RECIPE_AGGREGATOR_MATCH_MODE".
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+type (
+	// configValue is a scalar as it is written, so 010120 is not read as an octal number, or a list of scalars
+	// joined by commas.
+	configValue string
+	// configEntry is a top-level value of a config file, a configValue or a section.
+	configEntry struct {
+		value   configValue
+		section map[string]configValue
+	}
+)
+
+func (v *configValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var scalar string
+	if err := unmarshal(&scalar); err == nil {
+		*v = configValue(scalar)
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return errors.New("unsupported value, want a scalar or a list of scalars")
+	}
+	*v = configValue(strings.Join(list, ","))
+
+	return nil
+}
+
+func (e *configEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&e.section); err == nil {
+		return nil
+	}
+	e.section = nil
+
+	return unmarshal(&e.value)
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    Config
+		wantErr bool
+	}{
+		{"Values and sections", `
+postcode: "010120"
+names: [Potato, Veggie]
+threshold: 0.7
+raw: true
+serve:
+  addr: ":9090"
+  max-body: 1024
+`, Config{map[string]string{"postcode": "010120", "names": "Potato,Veggie", "threshold": "0.7", "raw": "true"},
+			map[string]map[string]string{"serve": {"addr": ":9090", "max-body": "1024"}}}, false},
+		{"Empty", "", Config{map[string]string{}, map[string]map[string]string{}}, false},
+		{"Unquoted scalars", `
+postcode: 010120
+threshold: 0.70
+serve:
+  postcode: 0x10
+`, Config{map[string]string{"postcode": "010120", "threshold": "0.70"},
+			map[string]map[string]string{"serve": {"postcode": "0x10"}}}, false},
+		{"Null", "postcode:", Config{map[string]string{"postcode": ""}, map[string]map[string]string{}}, false},
+		{"Nested list", "names: [[Potato]]", Config{}, true},
+		{"Nested section", "serve: {tls: {cert: a.pem}}", Config{}, true},
+		{"Invalid YAML", "names: [Potato", Config{}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			createFile(c.content)
+			defer removeFile()
+
+			got, err := LoadConfig(stubFile)
+
+			if !reflect.DeepEqual(c.want, got) || (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantErr, got, err)
+			}
+		})
+	}
+}
+
+func TestConfigValue(t *testing.T) {
+	cfg := Config{map[string]string{"postcode": "10120", "addr": ":8080"},
+		map[string]map[string]string{"serve": {"addr": ":9090"}}}
+	cases := []struct {
+		name      string
+		command   string
+		option    string
+		want      string
+		wantFound bool
+	}{
+		{"Value", "aggregate", "postcode", "10120", true},
+		{"Section overrides value", "serve", "addr", ":9090", true},
+		{"Value of other command", "aggregate", "addr", ":8080", true},
+		{"Not found", "serve", "names", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got, found := cfg.Value(c.command, c.option); got != c.want || found != c.wantFound {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantFound, got, found)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("match-mode"); got != "RECIPE_AGGREGATOR_MATCH_MODE" {
+		t.Errorf("want: RECIPE_AGGREGATOR_MATCH_MODE, got: %v", got)
+	}
+}
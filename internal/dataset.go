@@ -0,0 +1,384 @@
+package internal
+
+import (
+	"context"
+	"sort"
+)
+
+// Bits of a packed delivery window: the weekday id, then From and To in 5 bits each.
+const (
+	windowHourBits = 5
+	windowHourMask = 1<<windowHourBits - 1
+)
+
+type (
+	// Dataset is a columnar in-memory copy of the records that is aggregated again with any Filter without parsing
+	// the input again. Strings are dictionary-encoded, so each column keeps the ids of its values, and the delivery
+	// windows are parsed and packed once per distinct delivery. The rows are indexed by postcode and by weekday, so
+	// the postcode and time count and the where conditions on postcode or weekday only read the rows they need.
+	// Records are added by Calculate, e.g. as the Calculator of Parse. Use NewDataset or LoadDataset to create it.
+	Dataset struct {
+		recipes, postcodes, deliveries, dates, weekdays, values stringDict
+
+		recipe, postcode, delivery, date []uint32
+		attrNames                        []string
+		attrs                            [][]uint32
+
+		// windows has the packed window of each delivery and dateValues the valid date of each date, or "".
+		windows    []uint32
+		dateValues []string
+
+		byPostcode, byWeekday [][]uint32
+
+		recipeCounts, postcodeCounts, dateCounts []int
+	}
+	// stringDict dictionary-encodes strings: each distinct value has an id, in the order it was added. The id of ""
+	// is 0.
+	stringDict struct {
+		values []string
+		ids    map[string]uint32
+	}
+)
+
+// NewDataset creates an empty Dataset.
+func NewDataset() *Dataset {
+	d := &Dataset{recipes: newStringDict(), postcodes: newStringDict(), deliveries: newStringDict(),
+		dates: newStringDict(), weekdays: newStringDict(), values: newStringDict()}
+	d.windows, d.dateValues = []uint32{0}, []string{""}
+	d.byPostcode, d.byWeekday = [][]uint32{nil}, [][]uint32{nil}
+	d.recipeCounts, d.postcodeCounts, d.dateCounts = []int{0}, []int{0}, []int{0}
+
+	return d
+}
+
+// LoadDataset parses files, whose records are decoded with schema and normalized by recipes and postcodes, which
+// might be nil, into a new Dataset. It returns the Dataset and the Parse counts of all the files.
+// It returns an error if a file cannot be read or parsed and ctx.Err() if ctx is done before the end of the files.
+func LoadDataset(ctx context.Context, files []string, schema Schema, recipes *RecipeNormalizer,
+	postcodes *PostcodeNormalizer) (d *Dataset, parsed int, ignored int, err error) {
+	d = NewDataset()
+	calc := NewNormalizingCalculator(d, recipes, postcodes)
+	for _, file := range files {
+		p, i, err := ParseContext(ctx, file, schema, calc, false)
+		parsed, ignored = parsed+p, ignored+i
+		if err != nil {
+			return d, parsed, ignored, err
+		}
+	}
+
+	return d, parsed, ignored, nil
+}
+
+func newStringDict() stringDict {
+	return stringDict{values: []string{""}, ids: map[string]uint32{"": 0}}
+}
+
+// id returns the id of s, adding it if it is new.
+func (sd *stringDict) id(s string) uint32 {
+	if id, ok := sd.ids[s]; ok {
+		return id
+	}
+
+	id := uint32(len(sd.values))
+	sd.values = append(sd.values, s)
+	sd.ids[s] = id
+
+	return id
+}
+
+// Len returns the number of records of d.
+func (d *Dataset) Len() int {
+	return len(d.recipe)
+}
+
+// Calculate adds r to d.
+func (d *Dataset) Calculate(r Record) {
+	row := uint32(len(d.recipe))
+
+	recipe := d.recipes.id(r.Recipe)
+	if int(recipe) == len(d.recipeCounts) {
+		d.recipeCounts = append(d.recipeCounts, 0)
+	}
+	d.recipeCounts[recipe]++
+	d.recipe = append(d.recipe, recipe)
+
+	postcode := d.postcodes.id(r.Postcode)
+	if int(postcode) == len(d.postcodeCounts) {
+		d.postcodeCounts = append(d.postcodeCounts, 0)
+		d.byPostcode = append(d.byPostcode, nil)
+	}
+	d.postcodeCounts[postcode]++
+	d.byPostcode[postcode] = append(d.byPostcode[postcode], row)
+	d.postcode = append(d.postcode, postcode)
+
+	delivery := d.deliveries.id(r.Delivery)
+	if int(delivery) == len(d.windows) {
+		d.windows = append(d.windows, d.packWindow(r))
+	}
+	d.delivery = append(d.delivery, delivery)
+	if w := d.windows[delivery]; w != 0 {
+		weekday := w >> (2 * windowHourBits)
+		d.byWeekday[weekday] = append(d.byWeekday[weekday], row)
+	}
+
+	date := d.dates.id(r.DeliveryDate)
+	if int(date) == len(d.dateValues) {
+		value, _ := r.deliveryDate()
+		d.dateValues = append(d.dateValues, value)
+		d.dateCounts = append(d.dateCounts, 0)
+	}
+	d.dateCounts[date]++
+	d.date = append(d.date, date)
+
+	d.addAttributes(r.Attributes, int(row))
+}
+
+// packWindow packs the delivery window of r. It is 0 if the delivery is invalid, as a valid From is never 0.
+func (d *Dataset) packWindow(r Record) uint32 {
+	w, ok := r.deliveryWindow()
+	if !ok {
+		return 0
+	}
+
+	weekday := d.weekdays.id(w.Weekday)
+	if int(weekday) == len(d.byWeekday) {
+		d.byWeekday = append(d.byWeekday, nil)
+	}
+
+	return weekday<<(2*windowHourBits) | uint32(w.From)<<windowHourBits | uint32(w.To)
+}
+
+// addAttributes adds the attributes of the record at row. An attribute seen for the first time gets a column that
+// is empty for the previous records.
+func (d *Dataset) addAttributes(attrs map[string]string, row int) {
+	for name := range attrs {
+		if !contains(d.attrNames, name) {
+			d.attrNames = append(d.attrNames, name)
+			d.attrs = append(d.attrs, make([]uint32, row))
+		}
+	}
+	for i, name := range d.attrNames {
+		d.attrs[i] = append(d.attrs[i], d.values.id(attrs[name]))
+	}
+}
+
+// window unpacks the delivery window of row. It returns false if the delivery is invalid.
+func (d *Dataset) window(row uint32) (DeliveryWindow, bool) {
+	w := d.windows[d.delivery[row]]
+	if w == 0 {
+		return DeliveryWindow{}, false
+	}
+
+	return DeliveryWindow{d.weekdays.values[w>>(2*windowHourBits)], int(w >> windowHourBits & windowHourMask),
+		int(w & windowHourMask)}, true
+}
+
+// record returns the record at row as it was added, with its delivery already parsed.
+func (d *Dataset) record(row uint32) Record {
+	r := Record{
+		Postcode:     d.postcodes.values[d.postcode[row]],
+		Recipe:       d.recipes.values[d.recipe[row]],
+		Delivery:     d.deliveries.values[d.delivery[row]],
+		DeliveryDate: d.dates.values[d.date[row]],
+	}
+	r.window, _ = d.window(row)
+	if len(d.attrNames) > 0 {
+		r.Attributes = make(map[string]string, len(d.attrNames))
+		for i, name := range d.attrNames {
+			r.Attributes[name] = d.values.values[d.attrs[i][row]]
+		}
+	}
+
+	return r
+}
+
+// Aggregate aggregates the records of d with filter. The Aggregation is the one of a SummaryCalculator of filter
+// that calculated the same records, but each recipe name is matched once and the counts are added by id.
+func (d *Dataset) Aggregate(filter Filter) Aggregation {
+	s := NewSummaryCalculator(filter)
+	rows, all := d.selectRows(s.recordFilter)
+
+	recipeCounts, postcodeCounts, dateCounts := d.recipeCounts, d.postcodeCounts, d.dateCounts
+	if !all {
+		recipeCounts = make([]int, len(d.recipeCounts))
+		postcodeCounts = make([]int, len(d.postcodeCounts))
+		dateCounts = make([]int, len(d.dateCounts))
+		for _, row := range rows {
+			recipeCounts[d.recipe[row]]++
+			postcodeCounts[d.postcode[row]]++
+			dateCounts[d.date[row]]++
+		}
+	}
+
+	for id, n := range recipeCounts {
+		if n == 0 {
+			continue
+		}
+		recipe := d.recipes.values[id]
+		s.uniqueRecipesCache[recipe] = n
+		if nm := s.matchName(recipe); len(nm.Terms) > 0 {
+			nm.DeliveryCount = n
+			s.nameMatchDetails[recipe] = nm
+			s.nameMatchesCache = append(s.nameMatchesCache, recipe)
+		}
+	}
+	sort.Strings(s.nameMatchesCache)
+	for id, n := range postcodeCounts {
+		if n > 0 {
+			s.busiestPostcode[d.postcodes.values[id]] = n
+		}
+	}
+	for id, n := range dateCounts {
+		if n > 0 && d.dateValues[id] != "" {
+			s.dateCounts[d.dateValues[id]] += n
+		}
+	}
+	if len(filter.GroupBy) > 0 {
+		d.eachRow(rows, all, func(row uint32) {
+			s.groupCounts[s.groupKey(d.record(row))]++
+		})
+	}
+	d.countPostcodeAndTime(&s, rows, all)
+
+	return s.Aggregate()
+}
+
+// eachRow calls fn with each row of rows, or with every row of d if all is set.
+func (d *Dataset) eachRow(rows []uint32, all bool, fn func(row uint32)) {
+	if !all {
+		for _, row := range rows {
+			fn(row)
+		}
+		return
+	}
+
+	for row := range d.recipe {
+		fn(uint32(row))
+	}
+}
+
+// countPostcodeAndTime counts the selected rows of the Filter.Postcode of s delivered in its time range, as
+// SummaryCalculator does. Without a selection, only the rows of the postcode are read.
+func (d *Dataset) countPostcodeAndTime(s *SummaryCalculator, rows []uint32, all bool) {
+	tr := s.filterTimeRange
+	id, ok := d.postcodes.ids[s.Filter.Postcode]
+	if !ok || tr.err != nil {
+		return
+	}
+	if all {
+		rows = d.byPostcode[id]
+	}
+
+	n := 0
+	for _, row := range rows {
+		if w, ok := d.window(row); ok && d.postcode[row] == id && w.From <= tr.From && w.To <= tr.To {
+			n++
+		}
+	}
+	if n > 0 {
+		s.postcodeAndTimeCount = PostcodeAndTimeCount{s.Filter.Postcode, tr.from, tr.to, n}
+	}
+}
+
+// selectRows returns the rows included by f, sorted. It returns true instead if f includes every row.
+func (d *Dataset) selectRows(f recordFilter) ([]uint32, bool) {
+	if f.invalid {
+		return nil, false
+	}
+	if f.from == "" && f.to == "" && f.where == nil {
+		return nil, true
+	}
+
+	candidates, indexed := d.indexedRows(f.where)
+	var rows []uint32
+	d.eachRow(candidates, !indexed, func(row uint32) {
+		if f.from != "" || f.to != "" {
+			date := d.dateValues[d.date[row]]
+			if date == "" || (f.from != "" && date < f.from) || (f.to != "" && date > f.to) {
+				return
+			}
+		}
+		if f.where == nil || f.where.Match(d.record(row)) {
+			rows = append(rows, row)
+		}
+	})
+
+	return rows, false
+}
+
+// indexedRows returns the rows that might match e according to the postcode and weekday indexes, sorted, so only
+// them are checked by e. It returns false if e cannot be narrowed by the indexes: e is nil or neither an equality
+// nor an IN on the postcode or the weekday, nor an AND with one of them, nor an OR of them.
+func (d *Dataset) indexedRows(e Expr) ([]uint32, bool) {
+	switch e := e.(type) {
+	case compareExpr:
+		if e.op == "=" {
+			return d.rowsOf(e.field, []string{e.value})
+		}
+	case inExpr:
+		if !e.negate {
+			return d.rowsOf(e.field, e.values)
+		}
+	case andExpr:
+		left, leftOK := d.indexedRows(e.left)
+		right, rightOK := d.indexedRows(e.right)
+		if leftOK && (!rightOK || len(left) <= len(right)) {
+			return left, true
+		}
+		return right, rightOK
+	case orExpr:
+		left, leftOK := d.indexedRows(e.left)
+		right, rightOK := d.indexedRows(e.right)
+		if leftOK && rightOK {
+			return sortedRows(left, right), true
+		}
+	}
+
+	return nil, false
+}
+
+// rowsOf returns the rows whose field has one of values, sorted. Weekdays are compared by canonicalWeekday, as the
+// values of a where are. It returns false if field is neither postcode nor weekday.
+func (d *Dataset) rowsOf(field string, values []string) ([]uint32, bool) {
+	var lists [][]uint32
+	switch field {
+	case "postcode":
+		for _, v := range values {
+			if id, ok := d.postcodes.ids[v]; ok {
+				lists = append(lists, d.byPostcode[id])
+			}
+		}
+	case "weekday":
+		for id, weekday := range d.weekdays.values {
+			if day, _ := canonicalWeekday(weekday); weekday != "" && contains(values, day) {
+				lists = append(lists, d.byWeekday[id])
+			}
+		}
+	default:
+		return nil, false
+	}
+
+	return sortedRows(lists...), true
+}
+
+// sortedRows merges lists of rows into a sorted list without duplicates.
+func sortedRows(lists ...[]uint32) []uint32 {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+
+	var rows []uint32
+	for _, l := range lists {
+		rows = append(rows, l...)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i] < rows[j] })
+
+	unique := rows[:0]
+	for _, row := range rows {
+		if len(unique) == 0 || row != unique[len(unique)-1] {
+			unique = append(unique, row)
+		}
+	}
+
+	return unique
+}
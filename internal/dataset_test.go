@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// datasetFixture adds dates, attributes, abbreviated weekdays and an invalid record to the records of sampleFile.
+const datasetFixture = `{"postcode": "10120", "recipe": "Creamy Dill Chicken", "delivery": "Saturday 9AM - 2PM",
+"delivery_date": "2026-10-03", "box_size": 2}
+{"postcode": "10120", "recipe": "Creamy Dill Chicken", "delivery": "Sunday 10AM - 3PM",
+"delivery_date": "2026-10-04T10:00:00Z", "box_size": 4}
+{"postcode": "10121", "recipe": "Veggie Lasagna", "delivery": "Sat 11AM - 1PM", "delivery_date": "2026-10-03"}
+{"postcode": "10120", "recipe": "Veggie Lasagna", "delivery": "10AM - 3PM", "box_size": 4}`
+
+func TestDatasetAggregate(t *testing.T) {
+	createFile(datasetFixture)
+	defer removeFile()
+	schema := Schema{Attributes: []Attribute{{"box_size", "box_size"}}}
+	files := []string{sampleFile, stubFile}
+
+	d, parsed, ignored, err := LoadDataset(context.Background(), files, schema, nil, nil)
+	if err != nil || parsed != 23 || ignored != 1 || d.Len() != parsed {
+		t.Fatalf("LoadDataset, want: %v %v %v, got: %v %v %v", 23, 1, nil, parsed, ignored, err)
+	}
+
+	with := func(change func(f *Filter)) Filter {
+		f := regularFilter
+		change(&f)
+		return f
+	}
+	cases := []struct {
+		name   string
+		filter Filter
+	}{
+		{"Regular", regularFilter},
+		{"Postcode and time", with(func(f *Filter) { f.Postcode, f.TimeRange = "10120", "Saturday 10AM - 3PM" })},
+		{"Unknown postcode", with(func(f *Filter) { f.Postcode = "99999" })},
+		{"Fuzzy names", with(func(f *Filter) { f.Recipes, f.MatchMode = []string{"Chiken"}, FuzzyMatch })},
+		{"Postcode distribution", with(func(f *Filter) { f.PostcodeDistribution = true })},
+		{"Date range", with(func(f *Filter) { f.FromDate, f.ToDate = "2026-10-03", "2026-10-03" })},
+		{"Indexed where", with(func(f *Filter) { f.Where = "postcode in (10120, 10121) AND weekday = sat" })},
+		{"Indexed OR", with(func(f *Filter) { f.Where = "postcode = 10186 OR weekday in (Sun, Monday)" })},
+		{"Unindexed where", with(func(f *Filter) { f.Where = "recipe contains Chicken OR box_size > 2" })},
+		{"NOT", with(func(f *Filter) { f.Where = "NOT weekday = Wed" })},
+		{"Group by", with(func(f *Filter) { f.GroupBy = []string{"postcode", "box_size"} })},
+		{"Where and group by", with(func(f *Filter) {
+			f.Where, f.GroupBy = "weekday = Wednesday", []string{"recipe"}
+		})},
+		{"Invalid where", with(func(f *Filter) { f.Where = "postcode =" })},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			want := NewSummaryCalculator(c.filter)
+			for _, file := range files {
+				if _, _, err := ParseContext(context.Background(), file, schema, &want, false); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := d.Aggregate(c.filter); !reflect.DeepEqual(want.Aggregate(), got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, want.Aggregate(), got)
+			}
+		})
+	}
+}
+
+func TestDatasetIndexedRows(t *testing.T) {
+	createFile(datasetFixture)
+	defer removeFile()
+	d, _, _, err := LoadDataset(context.Background(), []string{stubFile}, DefaultSchema, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		where       string
+		wantRows    []uint32
+		wantIndexed bool
+	}{
+		{"postcode = 10120", []uint32{0, 1}, true},
+		{"weekday in (sat)", []uint32{0, 2}, true},
+		{"postcode = 10120 AND weekday = Sun", []uint32{1}, true},
+		{"postcode = 10121 OR weekday = Sunday", []uint32{1, 2}, true},
+		{"postcode = 10120 AND recipe contains Veggie", []uint32{0, 1}, true},
+		{"postcode = 99999", nil, true},
+		{"postcode = 10120 OR recipe contains Veggie", nil, false},
+		{"postcode != 10120", nil, false},
+		{"weekday not in (Sat)", nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.where, func(t *testing.T) {
+			e, err := ParseWhere(c.where)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rows, indexed := d.indexedRows(e)
+			if c.wantIndexed != indexed || (len(c.wantRows) > 0 || len(rows) > 0) && !reflect.DeepEqual(c.wantRows, rows) {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.where, c.wantRows, c.wantIndexed, rows, indexed)
+			}
+		})
+	}
+}
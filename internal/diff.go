@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type (
+	// CountDelta is the change of a count from a base Aggregation to a head Aggregation. PercentChange is the delta in
+	// percent of Base, rounded to two decimals. It is nil if Base is zero.
+	CountDelta struct {
+		Base          int      `json:"base"`
+		Head          int      `json:"head"`
+		Delta         int      `json:"delta"`
+		PercentChange *float64 `json:"percent_change"`
+	}
+	// RecipeDelta is the change of the delivery count of a recipe.
+	RecipeDelta struct {
+		Recipe string `json:"recipe"`
+		CountDelta
+	}
+	// BusiestPostcodeDiff is the busiest postcode of both aggregations. Changed is true if the postcodes differ.
+	BusiestPostcodeDiff struct {
+		Base    BusiestPostcode `json:"base"`
+		Head    BusiestPostcode `json:"head"`
+		Changed bool            `json:"changed"`
+	}
+	// PostcodeAndTimeDiff is the change of the deliveries counted by the postcode and time filter. Base and Head show
+	// the filter of each aggregation, which might differ when saved aggregations are compared.
+	PostcodeAndTimeDiff struct {
+		Base PostcodeAndTimeCount `json:"base"`
+		Head PostcodeAndTimeCount `json:"head"`
+		CountDelta
+	}
+	// NameMatchesDiff is the change of the recipes and deliveries matched by the names filter.
+	NameMatchesDiff struct {
+		Appeared    []string `json:"appeared"`
+		Disappeared []string `json:"disappeared"`
+		CountDelta
+	}
+	// AggregationDiff is the difference between two aggregations. Appeared has the recipes that are only in head and
+	// Disappeared the ones that are only in base.
+	AggregationDiff struct {
+		UniqueRecipes   CountDelta          `json:"unique_recipe_count"`
+		RecipeDeltas    []RecipeDelta       `json:"count_per_recipe"`
+		Appeared        []string            `json:"appeared_recipes"`
+		Disappeared     []string            `json:"disappeared_recipes"`
+		BusiestPostcode BusiestPostcodeDiff `json:"busiest_postcode"`
+		PostcodeAndTime PostcodeAndTimeDiff `json:"count_per_postcode_and_time"`
+		NameMatches     NameMatchesDiff     `json:"match_by_name"`
+	}
+)
+
+// DiffAggregations compares base to head. RecipeDeltas has every recipe of any of them sorted by name.
+func DiffAggregations(base, head Aggregation) AggregationDiff {
+	counts := make(map[string]*RecipeDelta)
+	for _, rc := range base.RecipeCount {
+		counts[rc.Recipe] = &RecipeDelta{Recipe: rc.Recipe, CountDelta: CountDelta{Base: rc.Count}}
+	}
+	for _, rc := range head.RecipeCount {
+		if d, ok := counts[rc.Recipe]; ok {
+			d.Head = rc.Count
+		} else {
+			counts[rc.Recipe] = &RecipeDelta{Recipe: rc.Recipe, CountDelta: CountDelta{Head: rc.Count}}
+		}
+	}
+
+	diff := AggregationDiff{
+		UniqueRecipes: newCountDelta(base.UniqueRecipeName, head.UniqueRecipeName),
+		RecipeDeltas:  make([]RecipeDelta, 0, len(counts)),
+		Appeared:      []string{},
+		Disappeared:   []string{},
+		BusiestPostcode: BusiestPostcodeDiff{
+			Base:    base.BusiestPostcode,
+			Head:    head.BusiestPostcode,
+			Changed: base.BusiestPostcode.Postcode != head.BusiestPostcode.Postcode,
+		},
+		PostcodeAndTime: PostcodeAndTimeDiff{
+			Base:       base.PostcodeAndTimeCount,
+			Head:       head.PostcodeAndTimeCount,
+			CountDelta: newCountDelta(base.PostcodeAndTimeCount.DeliveryCount, head.PostcodeAndTimeCount.DeliveryCount),
+		},
+		NameMatches: NameMatchesDiff{
+			Appeared:    missing(head.NameMatches, base.NameMatches),
+			Disappeared: missing(base.NameMatches, head.NameMatches),
+			CountDelta:  newCountDelta(base.NameMatchesCount, head.NameMatchesCount),
+		},
+	}
+	for _, d := range counts {
+		d.CountDelta = newCountDelta(d.Base, d.Head)
+		diff.RecipeDeltas = append(diff.RecipeDeltas, *d)
+	}
+	sort.Slice(diff.RecipeDeltas, func(i, j int) bool {
+		return diff.RecipeDeltas[i].Recipe < diff.RecipeDeltas[j].Recipe
+	})
+	for _, d := range diff.RecipeDeltas {
+		switch {
+		case d.Base == 0:
+			diff.Appeared = append(diff.Appeared, d.Recipe)
+		case d.Head == 0:
+			diff.Disappeared = append(diff.Disappeared, d.Recipe)
+		}
+	}
+
+	return diff
+}
+
+func (d AggregationDiff) String() string {
+	str, err := json.MarshalIndent(d, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+
+	return string(str)
+}
+
+// Text returns d in a human-readable form: the summary of the changes followed by a table of the recipe deltas.
+func (d AggregationDiff) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Unique recipes: %v\n", d.UniqueRecipes)
+	fmt.Fprintf(&b, "Busiest postcode: %s (%d) -> %s (%d)\n", d.BusiestPostcode.Base.Postcode,
+		d.BusiestPostcode.Base.DeliveryCount, d.BusiestPostcode.Head.Postcode, d.BusiestPostcode.Head.DeliveryCount)
+	filter := d.PostcodeAndTime.Head
+	if filter.Postcode == "" {
+		// An aggregation without deliveries in the time range does not show the filter.
+		filter = d.PostcodeAndTime.Base
+	}
+	fmt.Fprintf(&b, "Deliveries to %s from %s to %s: %v\n", filter.Postcode, filter.From, filter.To,
+		d.PostcodeAndTime.CountDelta)
+	fmt.Fprintf(&b, "Deliveries matched by name: %v\n", d.NameMatches.CountDelta)
+	writeNames(&b, "Matches appeared", d.NameMatches.Appeared)
+	writeNames(&b, "Matches disappeared", d.NameMatches.Disappeared)
+	writeNames(&b, "Recipes appeared", d.Appeared)
+	writeNames(&b, "Recipes disappeared", d.Disappeared)
+
+	b.WriteString("\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Recipe\tBase\tHead\tDelta\tChange")
+	for _, rd := range d.RecipeDeltas {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%+d\t%s\n", rd.Recipe, rd.Base, rd.Head, rd.Delta, rd.percent())
+	}
+	w.Flush()
+
+	return b.String()
+}
+
+// String returns the change as "base -> head (delta, percent)". E.g. "4 -> 5 (+1, +25.00%)".
+func (c CountDelta) String() string {
+	return fmt.Sprintf("%d -> %d (%+d, %s)", c.Base, c.Head, c.Delta, c.percent())
+}
+
+func (c CountDelta) percent() string {
+	if c.PercentChange == nil {
+		return "new"
+	}
+
+	return fmt.Sprintf("%+.2f%%", *c.PercentChange)
+}
+
+// ReadAggregation reads an Aggregation saved by the aggregate command, with or without the leading ConsoleClear.
+// It returns false if the file is not an Aggregation, e.g. a file of records.
+// It returns an error if the file cannot be read or the Aggregation cannot be decoded.
+func ReadAggregation(path string) (Aggregation, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Aggregation{}, false, fmt.Errorf("error to read [file=%v]: %v", path, err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if prefix, _ := br.Peek(len(ConsoleClear)); bytes.Equal(prefix, []byte(ConsoleClear)) {
+		if _, err := br.Discard(len(ConsoleClear)); err != nil {
+			return Aggregation{}, false, fmt.Errorf("error to read [file=%v]: %v", path, err)
+		}
+	}
+	isArray, err := startsWithArray(br)
+	if err != nil {
+		return Aggregation{}, false, fmt.Errorf("error to read [file=%v]: %v", path, err)
+	}
+	if isArray {
+		return Aggregation{}, false, nil
+	}
+
+	// An empty file or an invalid JSON is left to the records parser to report.
+	var content json.RawMessage
+	if err := json.NewDecoder(br).Decode(&content); err != nil {
+		return Aggregation{}, false, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return Aggregation{}, false, nil
+	}
+	if _, ok := fields["count_per_recipe"]; !ok {
+		return Aggregation{}, false, nil
+	}
+
+	var a Aggregation
+	if err := json.Unmarshal(content, &a); err != nil {
+		return Aggregation{}, false, fmt.Errorf("error to decode aggregation [file=%v]: %v", path, err)
+	}
+
+	return a, true, nil
+}
+
+func newCountDelta(base, head int) CountDelta {
+	c := CountDelta{Base: base, Head: head, Delta: head - base}
+	if base != 0 {
+		p := math.Round(float64(c.Delta)*10000/float64(base)) / 100
+		c.PercentChange = &p
+	}
+
+	return c
+}
+
+// missing returns the names of a that are not in b.
+func missing(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, n := range b {
+		in[n] = true
+	}
+
+	names := []string{}
+	for _, n := range a {
+		if !in[n] {
+			names = append(names, n)
+		}
+	}
+
+	return names
+}
+
+func writeNames(w io.Writer, title string, names []string) {
+	if len(names) > 0 {
+		fmt.Fprintf(w, "%s: %s\n", title, strings.Join(names, ", "))
+	}
+}
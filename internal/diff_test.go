@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffAggregations(t *testing.T) {
+	cases := []struct {
+		name            string
+		base            []RecipeCount
+		head            []RecipeCount
+		want            []RecipeDelta
+		wantAppeared    []string
+		wantDisappeared []string
+	}{
+		{"Deltas", []RecipeCount{{"Creamy Dill Chicken", 2}, {"Speedy Steak Fajitas", 1}},
+			[]RecipeCount{{"Cherry Balsamic Pork Chops", 3}, {"Creamy Dill Chicken", 1}},
+			[]RecipeDelta{{"Cherry Balsamic Pork Chops", countDelta(0, 3, 3, nil)},
+				{"Creamy Dill Chicken", countDelta(2, 1, -1, percent(-50))},
+				{"Speedy Steak Fajitas", countDelta(1, 0, -1, percent(-100))}},
+			[]string{"Cherry Balsamic Pork Chops"}, []string{"Speedy Steak Fajitas"}},
+		{"Rounded percentage", []RecipeCount{{"Creamy Dill Chicken", 3}}, []RecipeCount{{"Creamy Dill Chicken", 4}},
+			[]RecipeDelta{{"Creamy Dill Chicken", countDelta(3, 4, 1, percent(33.33))}}, []string{}, []string{}},
+		{"Empty", nil, nil, []RecipeDelta{}, []string{}, []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := DiffAggregations(Aggregation{RecipeCount: c.base}, Aggregation{RecipeCount: c.head})
+
+			if !reflect.DeepEqual(c.want, got.RecipeDeltas) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got.RecipeDeltas)
+			}
+			if !reflect.DeepEqual(c.wantAppeared, got.Appeared) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantAppeared, got.Appeared)
+			}
+			if !reflect.DeepEqual(c.wantDisappeared, got.Disappeared) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantDisappeared, got.Disappeared)
+			}
+		})
+	}
+}
+
+func TestDiffAggregationsFilters(t *testing.T) {
+	base := Aggregation{
+		UniqueRecipeName:     2,
+		BusiestPostcode:      BusiestPostcode{"10120", 4},
+		PostcodeAndTimeCount: PostcodeAndTimeCount{"10120", "10AM", "3PM", 4},
+		NameMatches:          NamesMatches{"Mushroom Risotto", "Veggie Burger"},
+		NameMatchesCount:     5,
+	}
+	head := Aggregation{
+		UniqueRecipeName:     3,
+		BusiestPostcode:      BusiestPostcode{"10224", 6},
+		PostcodeAndTimeCount: PostcodeAndTimeCount{"10120", "10AM", "3PM", 5},
+		NameMatches:          NamesMatches{"Potato Gratin", "Veggie Burger"},
+		NameMatchesCount:     5,
+	}
+
+	got := DiffAggregations(base, head)
+
+	cases := []struct {
+		name string
+		want interface{}
+		got  interface{}
+	}{
+		{"Unique recipes", countDelta(2, 3, 1, percent(50)), got.UniqueRecipes},
+		{"Busiest postcode", BusiestPostcodeDiff{base.BusiestPostcode, head.BusiestPostcode, true},
+			got.BusiestPostcode},
+		{"Postcode and time", PostcodeAndTimeDiff{base.PostcodeAndTimeCount, head.PostcodeAndTimeCount,
+			countDelta(4, 5, 1, percent(25))}, got.PostcodeAndTime},
+		{"Name matches", NameMatchesDiff{[]string{"Potato Gratin"}, []string{"Mushroom Risotto"},
+			countDelta(5, 5, 0, percent(0))}, got.NameMatches},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if !reflect.DeepEqual(c.want, c.got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, c.got)
+			}
+		})
+	}
+}
+
+func TestAggregationDiffText(t *testing.T) {
+	diff := DiffAggregations(
+		Aggregation{RecipeCount: []RecipeCount{{"Creamy Dill Chicken", 2}}, BusiestPostcode: BusiestPostcode{"10120", 2}},
+		Aggregation{RecipeCount: []RecipeCount{{"Cherry Balsamic Pork Chops", 1}, {"Creamy Dill Chicken", 3}},
+			BusiestPostcode: BusiestPostcode{"10224", 3}})
+
+	text := diff.Text()
+
+	for _, want := range []string{
+		"Busiest postcode: 10120 (2) -> 10224 (3)",
+		"Recipes appeared: Cherry Balsamic Pork Chops",
+		"+50.00%",
+		"new",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text, want: %v, got: %v", want, text)
+		}
+	}
+}
+
+func TestReadAggregation(t *testing.T) {
+	cases := []struct {
+		name        string
+		content     string
+		want        bool
+		wantRecipes int
+	}{
+		{"Aggregation", `{"unique_recipe_count": 1, "count_per_recipe": [{"recipe": "Creamy Dill Chicken", "count": 2}]}`,
+			true, 1},
+		{"Aggregation printed to the console", ConsoleClear + `{"count_per_recipe": []}`, true, 0},
+		{"Array of records", `[{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"}]`,
+			false, 0},
+		{"Records stream", `{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"}`,
+			false, 0},
+		{"Empty", "", false, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			createFile(c.content)
+			defer removeFile()
+
+			got, ok, err := ReadAggregation(stubFile)
+
+			if err != nil {
+				t.Errorf("%s, want: %v, got: %v", c.name, nil, err)
+			}
+			if ok != c.want {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, ok)
+			}
+			if len(got.RecipeCount) != c.wantRecipes {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantRecipes, len(got.RecipeCount))
+			}
+		})
+	}
+}
+
+func countDelta(base, head, delta int, percent *float64) CountDelta {
+	return CountDelta{Base: base, Head: head, Delta: delta, PercentChange: percent}
+}
+
+func percent(p float64) *float64 {
+	return &p
+}
@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultExplorerTop is the number of top recipes shown by an Explorer.
+const DefaultExplorerTop = 10
+
+type (
+	// Aggregator aggregates records loaded once with any Filter, so they are aggregated again without parsing them.
+	// E.g. Dataset.
+	Aggregator interface {
+		Aggregate(filter Filter) Aggregation
+	}
+	// Explorer explores the aggregations of Aggregator interactively: each command changes Filter and the aggregation
+	// is shown again. Schema checks the fields of Filter.Where and Postcodes, which might be nil, normalizes
+	// Filter.Postcode as the records were normalized. RecordCount is the number of records of Aggregator and Top the
+	// number of top recipes shown. Use NewExplorer to create it.
+	Explorer struct {
+		Aggregator  Aggregator
+		Filter      Filter
+		Schema      Schema
+		Postcodes   *PostcodeNormalizer
+		RecordCount int
+		Top         int
+		aggregation Aggregation
+		took        time.Duration
+		status      string
+	}
+)
+
+// explorerHelp lists the commands of an Explorer.
+const explorerHelp = `Commands:
+  p, postcode <postcode>     Postcode of the deliveries counted by time, e.g. p 10120
+  r, timerange <range>       Time range of the deliveries counted by postcode, e.g. r Friday 10AM - 2PM
+  n, names <names>           Comma separated names matched against recipe names, e.g. n Veggie,Potato
+  m, mode <mode>             How names match: substring, word, prefix, regex, glob or fuzzy
+  t, threshold <threshold>   Minimum similarity (above 0, at most 1) of the fuzzy match mode
+  w, where [condition]       Condition the records must meet, as --where; no condition removes it
+  top <n>                    Number of top recipes shown
+  h, help                    Show this help
+  q, quit                    Quit`
+
+// NewExplorer creates an Explorer of aggregator with filter, which must be valid, and aggregates it.
+func NewExplorer(aggregator Aggregator, filter Filter, schema Schema, postcodes *PostcodeNormalizer,
+	recordCount int) *Explorer {
+	e := &Explorer{Aggregator: aggregator, Filter: filter, Schema: schema, Postcodes: postcodes,
+		RecordCount: recordCount, Top: DefaultExplorerTop}
+	e.aggregate()
+
+	return e
+}
+
+// Run shows the aggregation on out and executes the commands read from in, one per line, until quit or the end of in.
+func (e *Explorer) Run(in io.Reader, out io.Writer) error {
+	s := bufio.NewScanner(in)
+	for {
+		e.Render(out)
+		if !s.Scan() {
+			return s.Err()
+		}
+		if quit := e.Execute(s.Text()); quit {
+			return nil
+		}
+	}
+}
+
+// Execute executes a command of explorerHelp and aggregates again if the Filter changed. An invalid command or
+// Filter is reported by the next Render and leaves the Filter as it was.
+// It returns true if the command is quit.
+func (e *Explorer) Execute(line string) (quit bool) {
+	e.status = ""
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	name, arg := strings.ToLower(fields[0]), strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+
+	filter := e.Filter
+	switch name {
+	case "q", "quit", "exit":
+		return true
+	case "h", "help", "?":
+		e.status = explorerHelp
+		return false
+	case "top":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			e.status = fmt.Sprintf("Error: invalid top %q, want a positive number", arg)
+			return false
+		}
+		e.Top = n
+		return false
+	case "p", "postcode":
+		filter.Postcode = arg
+		if e.Postcodes != nil {
+			filter.Postcode = e.Postcodes.Normalize(arg)
+		}
+	case "r", "timerange":
+		filter.TimeRange = arg
+	case "n", "names":
+		filter.Recipes = strings.Split(arg, ",")
+	case "m", "mode":
+		filter.MatchMode = MatchMode(arg)
+	case "t", "threshold":
+		th, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			e.status = fmt.Sprintf("Error: invalid threshold %q: %v", arg, err)
+			return false
+		}
+		filter.Threshold = th
+	case "w", "where":
+		filter.Where = arg
+		if e.Postcodes != nil {
+			filter.Where = e.Postcodes.NormalizeWhere(arg)
+		}
+	default:
+		e.status = fmt.Sprintf("Error: unknown command %q, type help for the commands", fields[0])
+		return false
+	}
+
+	if err := filter.Validate(); err != nil {
+		e.status = fmt.Sprintf("Error: %v", err)
+		return false
+	}
+	if err := filter.ValidateFields(e.Schema); err != nil {
+		e.status = fmt.Sprintf("Error: %v", err)
+		return false
+	}
+	e.Filter = filter
+	e.aggregate()
+
+	return false
+}
+
+func (e *Explorer) aggregate() {
+	start := time.Now()
+	e.aggregation = e.Aggregator.Aggregate(e.Filter)
+	e.took = time.Since(start)
+}
+
+// Render clears the console and shows the Filter, its aggregation, the status of the last command and the prompt.
+func (e *Explorer) Render(out io.Writer) {
+	a := e.aggregation
+	fmt.Fprint(out, ConsoleClear)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Recipe explorer: %d records, aggregated in %v. Type help for the commands.\n\n", e.RecordCount,
+		e.took.Round(time.Microsecond))
+
+	where := e.Filter.Where
+	if where == "" {
+		where = "NA"
+	}
+	mode := string(e.Filter.MatchMode)
+	if e.Filter.MatchMode == FuzzyMatch {
+		mode += ", " + strconv.FormatFloat(e.Filter.Threshold, 'f', -1, 64)
+	}
+	fmt.Fprintf(w, "Filter\n  postcode\t%s\n  timerange\t%s\n  names\t%s (%s)\n  where\t%s\n\n", e.Filter.Postcode,
+		e.Filter.TimeRange, strings.Join(e.Filter.Recipes, ","), mode, where)
+
+	fmt.Fprintf(w, "Deliveries to %s from %s to %s\t%d\n", a.PostcodeAndTimeCount.Postcode, a.From, a.To,
+		a.PostcodeAndTimeCount.DeliveryCount)
+	fmt.Fprintf(w, "Busiest postcode\t%s (%d deliveries)\n", a.BusiestPostcode.Postcode,
+		a.BusiestPostcode.DeliveryCount)
+	fmt.Fprintf(w, "Unique recipes\t%d\n\n", a.UniqueRecipeName)
+
+	fmt.Fprintf(w, "Matches by name (%d)\n", len(a.NameMatches))
+	for _, name := range a.NameMatches {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+
+	top := topRecipes(a.RecipeCount, e.Top)
+	fmt.Fprintf(w, "\nTop %d recipes\n", len(top))
+	for _, rc := range top {
+		fmt.Fprintf(w, "  %d\t%s\n", rc.Count, rc.Recipe)
+	}
+	w.Flush()
+
+	if e.status != "" {
+		fmt.Fprintf(out, "\n%s\n", e.status)
+	}
+	fmt.Fprint(out, "\n> ")
+}
+
+// topRecipes returns the n recipes with more deliveries, by name if their counts are equal.
+func topRecipes(counts []RecipeCount, n int) []RecipeCount {
+	top := append([]RecipeCount(nil), counts...)
+	sort.SliceStable(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Recipe < top[j].Recipe
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+
+	return top
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func loadSampleDataset(t *testing.T) *Dataset {
+	d, _, _, err := LoadDataset(context.Background(), []string{sampleFile}, DefaultSchema, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func TestExplorerExecute(t *testing.T) {
+	withPostcode := regularFilter
+	withPostcode.Postcode = "10224"
+	withTimeRange := regularFilter
+	withTimeRange.TimeRange = "Wednesday 1AM - 7PM"
+	withNames := regularFilter
+	withNames.Recipes = []string{"Chicken", "Pork"}
+	withWhere := regularFilter
+	withWhere.Where = "weekday = Sat"
+
+	cases := []struct {
+		name       string
+		line       string
+		wantFilter Filter
+		wantStatus string
+		wantQuit   bool
+	}{
+		{"Postcode", "p 10224", withPostcode, "", false},
+		{"Time range", "timerange Wednesday 1AM - 7PM", withTimeRange, "", false},
+		{"Names", "N Chicken,Pork", withNames, "", false},
+		{"Where", "w weekday = Sat", withWhere, "", false},
+		{"Empty line", "  ", regularFilter, "", false},
+		{"Invalid postcode", "p 10224!", regularFilter, "Error: invalid postcode", false},
+		{"Unknown where field", "where box_size = 4", regularFilter, "Error: invalid where field", false},
+		{"Unknown command", "x", regularFilter, `Error: unknown command "x"`, false},
+		{"Help", "help", regularFilter, "Commands:", false},
+		{"Quit", "q", regularFilter, "", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := loadSampleDataset(t)
+			e := NewExplorer(d, regularFilter, DefaultSchema, nil, d.Len())
+
+			quit := e.Execute(c.line)
+
+			if c.wantQuit != quit {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantQuit, quit)
+			}
+			if !reflect.DeepEqual(c.wantFilter, e.Filter) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantFilter, e.Filter)
+			}
+			if !strings.HasPrefix(e.status, c.wantStatus) || (c.wantStatus == "") != (e.status == "") {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantStatus, e.status)
+			}
+			if want := d.Aggregate(e.Filter); !reflect.DeepEqual(want, e.aggregation) {
+				t.Errorf("%s, want: %v, got: %v", c.name, want, e.aggregation)
+			}
+		})
+	}
+}
+
+func TestExplorerRun(t *testing.T) {
+	d := loadSampleDataset(t)
+	e := NewExplorer(d, regularFilter, DefaultSchema, nil, d.Len())
+
+	var out strings.Builder
+	if err := e.Run(strings.NewReader("p 10224\nr Wednesday 1AM - 7PM\ntop 2\nq\nn Chicken\n"), &out); err != nil {
+		t.Fatalf("Run, want: %v, got: %v", nil, err)
+	}
+
+	screens := strings.Split(out.String(), ConsoleClear)
+	if len(screens) != 5 {
+		t.Fatalf("Screens, want: %v, got: %v", 5, len(screens))
+	}
+	last := screens[4]
+	for _, want := range []string{"20 records", "Deliveries to 10224 from 1AM to 7PM  1\n", "Top 2 recipes\n  3  " +
+		"Speedy Steak Fajitas\n  2  Cherry Balsamic Pork Chops\n\n> "} {
+		if !strings.Contains(last, want) {
+			t.Errorf("Run, want: %v, got: %v", want, last)
+		}
+	}
+}
+
+func TestExplorerRenderThreshold(t *testing.T) {
+	d := loadSampleDataset(t)
+	e := NewExplorer(d, regularFilter, DefaultSchema, nil, d.Len())
+
+	var out strings.Builder
+	if err := e.Run(strings.NewReader("m fuzzy\nt 0.7\nm word\nq\n"), &out); err != nil {
+		t.Fatalf("Run, want: %v, got: %v", nil, err)
+	}
+
+	screens := strings.Split(out.String(), ConsoleClear)
+	wants := map[int]string{2: "Mushroom (fuzzy, 0.8)\n", 3: "Mushroom (fuzzy, 0.7)\n", 4: "Mushroom (word)\n"}
+	for i, want := range wants {
+		if !strings.Contains(screens[i], want) {
+			t.Errorf("Screen %d, want: %v, got: %v", i, want, screens[i])
+		}
+	}
+}
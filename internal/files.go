@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// inputExtensions are the extensions of the files taken from a directory by ExpandPaths. Other files, such as the
+// states and checkpoints saved next to the inputs, are skipped.
+var inputExtensions = map[string]bool{".json": true, ".jsonl": true, ".ndjson": true}
+
+type (
+	// FileSummary is the summary of a file of a multi-file aggregation: its Parse counts and its own busiest postcode.
+	FileSummary struct {
+		File            string `json:"file"`
+		Parsed          int    `json:"parsed"`
+		Ignored         int    `json:"ignored"`
+		BusiestPostcode `json:"busiest_postcode"`
+	}
+	// MultiCalculator calculates each record with all of its Calculators.
+	MultiCalculator []Calculator
+	// PostcodeCounter is a Calculator that only counts the deliveries of each postcode.
+	PostcodeCounter map[string]int
+	// FilteredCalculator calculates with Calc only the records selected by the date range and the where of a
+	// Filter, as SummaryCalculator does. Use NewFilteredCalculator to create it.
+	FilteredCalculator struct {
+		recordFilter
+		Calc Calculator
+	}
+)
+
+// ExpandPaths returns the files of paths in order and without duplicates. A path is either a file, a directory, whose
+// .json, .jsonl and .ndjson files are taken sorted by name, or a pattern of filepath.Glob. E.g. "exports/2026-10-*.json".
+// It returns an error if a path does not exist, if a pattern is malformed or matches nothing or if a directory has
+// no input file.
+func ExpandPaths(paths []string) ([]string, error) {
+	var (
+		files []string
+		seen  = make(map[string]bool)
+	)
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		matches := []string{path}
+		if strings.ContainsAny(path, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(path); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", path, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no file matches %q", path)
+			}
+		}
+
+		for _, m := range matches {
+			found, err := expandPath(m)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range found {
+				if !seen[f] {
+					seen[f] = true
+					files = append(files, f)
+				}
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func expandPath(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error to read [file=%v]: %v", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("error to read [dir=%v]: %v", path, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && inputExtensions[strings.ToLower(filepath.Ext(e.Name()))] {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no input file in [dir=%v]", path)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Calculate delegates r to every Calculator of m.
+func (m MultiCalculator) Calculate(r Record) {
+	for _, c := range m {
+		c.Calculate(r)
+	}
+}
+
+// NewFilteredCalculator creates a FilteredCalculator that calculates with calc the records selected by filter.
+func NewFilteredCalculator(filter Filter, calc Calculator) FilteredCalculator {
+	return FilteredCalculator{newRecordFilter(filter), calc}
+}
+
+// Calculate delegates r to Calc if it is selected by the filter.
+func (f FilteredCalculator) Calculate(r Record) {
+	if f.includes(r) {
+		f.Calc.Calculate(r)
+	}
+}
+
+// Calculate counts the delivery of r.
+func (p PostcodeCounter) Calculate(r Record) {
+	p[r.Postcode]++
+}
+
+// Busiest returns the postcode with more deliveries, as SummaryCalculator.Aggregate does.
+func (p PostcodeCounter) Busiest() BusiestPostcode {
+	return calcBusiestPostCode(sortPostcodeCounts(p))
+}
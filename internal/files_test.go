@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2026-10-02.json", "2026-10-01.json", "2026-10-03.ndjson", "week.state",
+		"empty/.keep"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(names ...string) []string {
+		paths := make([]string, len(names))
+		for i, n := range names {
+			paths[i] = filepath.Join(dir, n)
+		}
+		return paths
+	}
+
+	cases := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{"File", join("week.state"), join("week.state"), false},
+		{"Files in order", join("2026-10-02.json", "2026-10-01.json"), join("2026-10-02.json", "2026-10-01.json"),
+			false},
+		{"Directory", []string{dir}, join("2026-10-01.json", "2026-10-02.json", "2026-10-03.ndjson"), false},
+		{"Glob", join("2026-10-*.json"), join("2026-10-01.json", "2026-10-02.json"), false},
+		{"No duplicates", append(join("2026-10-02.json"), dir, " "),
+			join("2026-10-02.json", "2026-10-01.json", "2026-10-03.ndjson"), false},
+		{"Missing file", join("2026-10-04.json"), nil, true},
+		{"Glob without match", join("2026-11-*.json"), nil, true},
+		{"Malformed glob", join("2026-10-[.json"), nil, true},
+		{"Directory without input files", join("empty"), nil, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ExpandPaths(c.in)
+
+			if (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantErr, err)
+			}
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestPostcodeCounter(t *testing.T) {
+	counter := make(PostcodeCounter)
+	calculator := NewSummaryCalculator(regularFilter)
+	calc := MultiCalculator{&calculator, counter}
+	for _, postcode := range []string{"10224", "10120", "10224", "10120", "10116"} {
+		calc.Calculate(Record{Postcode: postcode, Recipe: "Creamy Dill Chicken"})
+	}
+
+	want := BusiestPostcode{"10120", 2}
+
+	if got := counter.Busiest(); want != got {
+		t.Errorf("Busiest, want: %v, got: %v", want, got)
+	}
+	if got := calculator.Aggregate().BusiestPostcode; want != got {
+		t.Errorf("Aggregate, want: %v, got: %v", want, got)
+	}
+}
@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPartialInterval is the interval of the partial aggregations of WatchAggregate.
+const DefaultPartialInterval = time.Second
+
+type (
+	// GRPCServer implements pb.AggregatorServer. Each stream has its own SummaryCalculator built from the Filter of its
+	// first message over ServerConfig.DefaultFilter, as NewAggregateHandler does with the query parameters.
+	GRPCServer struct {
+		pb.UnimplementedAggregatorServer
+		cfg ServerConfig
+	}
+	// aggregateStream is the aggregation of the records received by a stream.
+	aggregateStream struct {
+		cfg        ServerConfig
+		calculator *SummaryCalculator
+		calc       Calculator
+		parsed     int
+		ignored    int
+	}
+)
+
+// NewGRPCServer creates a GRPCServer given cfg. Only DefaultFilter, Aliases and Regions are used.
+func NewGRPCServer(cfg ServerConfig) *GRPCServer {
+	return &GRPCServer{cfg: cfg.withDefaults()}
+}
+
+// ServeGRPC listens on addr and serves GRPCServer until ctx is done. Then it stops the server gracefully, waiting for
+// the streams in progress.
+// It returns an error if the server cannot listen or serve.
+func ServeGRPC(ctx context.Context, addr string, cfg ServerConfig) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer()
+	pb.RegisterAggregatorServer(srv, NewGRPCServer(cfg))
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+
+	return srv.Serve(lis)
+}
+
+// Aggregate calculates the records of the stream and returns the aggregation when the client closes it.
+func (s *GRPCServer) Aggregate(stream pb.Aggregator_AggregateServer) error {
+	agg := &aggregateStream{cfg: s.cfg}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			reply, err := agg.reply(true)
+			if err != nil {
+				return err
+			}
+			return stream.SendAndClose(reply)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := agg.add(req); err != nil {
+			return err
+		}
+	}
+}
+
+// WatchAggregate calculates the records of the stream, sends a partial aggregation every partial_interval_ms and
+// the final aggregation when the client closes the stream.
+func (s *GRPCServer) WatchAggregate(stream pb.Aggregator_WatchAggregateServer) error {
+	reqs := make(chan *pb.AggregateRequest)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case reqs <- req:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+
+	agg := &aggregateStream{cfg: s.cfg}
+	var ticks <-chan time.Time
+	for {
+		select {
+		case req := <-reqs:
+			if err := agg.add(req); err != nil {
+				return err
+			}
+			if ticks == nil {
+				interval := time.Duration(req.GetPartialIntervalMs()) * time.Millisecond
+				if interval <= 0 {
+					interval = DefaultPartialInterval
+				}
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				ticks = ticker.C
+			}
+		case <-ticks:
+			reply, _ := agg.reply(false)
+			if err := stream.Send(reply); err != nil {
+				return err
+			}
+		case err := <-errs:
+			if err != io.EOF {
+				return err
+			}
+			reply, err := agg.reply(true)
+			if err != nil {
+				return err
+			}
+			return stream.Send(reply)
+		}
+	}
+}
+
+// add calculates the records of req. The Filter is only read from the first request.
+func (a *aggregateStream) add(req *pb.AggregateRequest) error {
+	if err := a.init(req.GetFilter()); err != nil {
+		return err
+	}
+
+	for _, pr := range req.GetRecords() {
+		r := Record{Postcode: pr.GetPostcode(), Recipe: pr.GetRecipe(), Delivery: pr.GetDelivery(),
+			DeliveryDate: pr.GetDeliveryDate()}
+		r.parseDelivery()
+		if !r.IsValid() {
+			a.ignored++
+			continue
+		}
+
+		a.calc.Calculate(r)
+		a.parsed++
+	}
+
+	return nil
+}
+
+func (a *aggregateStream) init(f *pb.Filter) error {
+	if a.calculator != nil {
+		return nil
+	}
+
+	// A zero threshold is not set, as proto3 does not tell it apart.
+	var threshold *float64
+	if th := f.GetThreshold(); th != 0 {
+		threshold = &th
+	}
+	filter, recipes, postcodes, err := a.cfg.filter(filterParams{
+		Postcode:      f.GetPostcode(),
+		TimeRange:     f.GetTimeRange(),
+		Names:         f.GetNames(),
+		MatchMode:     f.GetMatchMode(),
+		Threshold:     threshold,
+		FromDate:      f.GetFromDate(),
+		ToDate:        f.GetToDate(),
+		Where:         f.GetWhere(),
+		GroupBy:       f.GetGroupBy(),
+		PostcodeGroup: f.GetPostcodeGroup(),
+		Raw:           f.GetRaw(),
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	calculator := NewSummaryCalculator(filter)
+	a.calculator = &calculator
+	a.calc = a.calculator
+	if recipes != nil {
+		a.calc = NewNormalizingCalculator(a.calc, recipes, postcodes)
+	}
+
+	return nil
+}
+
+// reply returns the aggregation so far. A stream without requests is aggregated with the default filter.
+func (a *aggregateStream) reply(final bool) (*pb.AggregateReply, error) {
+	if err := a.init(nil); err != nil {
+		return nil, err
+	}
+
+	return &pb.AggregateReply{
+		Aggregation: toProtoAggregation(a.calculator.Aggregate()),
+		Parsed:      int64(a.parsed),
+		Ignored:     int64(a.ignored),
+		Final:       final,
+	}, nil
+}
+
+func toProtoAggregation(a Aggregation) *pb.Aggregation {
+	pa := &pb.Aggregation{
+		UniqueRecipeCount: int64(a.UniqueRecipeName),
+		BusiestPostcode: &pb.Aggregation_PostcodeCount{
+			Postcode:      a.BusiestPostcode.Postcode,
+			DeliveryCount: int64(a.BusiestPostcode.DeliveryCount),
+		},
+		CountPerPostcodeAndTime: &pb.Aggregation_PostcodeAndTimeCount{
+			Postcode:      a.PostcodeAndTimeCount.Postcode,
+			From:          a.PostcodeAndTimeCount.From,
+			To:            a.PostcodeAndTimeCount.To,
+			DeliveryCount: int64(a.PostcodeAndTimeCount.DeliveryCount),
+		},
+		MatchByName:              append([]string(nil), a.NameMatches...),
+		MatchByNameDeliveryCount: int64(a.NameMatchesCount),
+	}
+	for _, rc := range a.RecipeCount {
+		pa.CountPerRecipe = append(pa.CountPerRecipe, &pb.Aggregation_RecipeCount{
+			Recipe: rc.Recipe,
+			Count:  int64(rc.Count),
+		})
+	}
+	for _, nm := range a.NameMatchDetails {
+		pa.MatchByNameDetails = append(pa.MatchByNameDetails, &pb.Aggregation_NameMatch{
+			Recipe:        nm.Recipe,
+			Score:         nm.Score,
+			Terms:         nm.Terms,
+			DeliveryCount: int64(nm.DeliveryCount),
+		})
+	}
+	for _, pc := range a.PostcodeCount {
+		pa.CountPerPostcode = append(pa.CountPerPostcode, &pb.Aggregation_PostcodeCount{
+			Postcode:      pc.Postcode,
+			DeliveryCount: int64(pc.DeliveryCount),
+		})
+	}
+	for _, dc := range a.DateCount {
+		pa.CountPerDate = append(pa.CountPerDate, &pb.Aggregation_DateCount{
+			Date:          dc.Date,
+			DeliveryCount: int64(dc.DeliveryCount),
+		})
+	}
+	for _, gc := range a.GroupCount {
+		pa.CountPerGroup = append(pa.CountPerGroup, &pb.Aggregation_GroupCount{
+			Group:         gc.Group,
+			DeliveryCount: int64(gc.DeliveryCount),
+		})
+	}
+
+	return pa
+}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/hellofreshdevtests/r1cm3d-recipe-count-test-2020/internal/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGRPCAggregate(t *testing.T) {
+	client := newGRPCClient(t)
+	cases := []struct {
+		name     string
+		reqs     []*pb.AggregateRequest
+		want     *pb.AggregateReply
+		wantCode codes.Code
+	}{
+		{"Records in batches", []*pb.AggregateRequest{{Records: grpcRecords[:2]}, {Records: grpcRecords[2:]}},
+			&pb.AggregateReply{Aggregation: &pb.Aggregation{
+				UniqueRecipeCount: 2,
+				CountPerRecipe: []*pb.Aggregation_RecipeCount{{Recipe: "Cherry Balsamic Pork Chops", Count: 1},
+					{Recipe: "Creamy Dill Chicken", Count: 2}},
+				BusiestPostcode: &pb.Aggregation_PostcodeCount{Postcode: "10224", DeliveryCount: 2},
+				CountPerPostcodeAndTime: &pb.Aggregation_PostcodeAndTimeCount{Postcode: "10120", From: "10AM",
+					To: "3PM", DeliveryCount: 1},
+			}, Parsed: 3, Ignored: 1, Final: true}, codes.OK},
+		{"Filter", []*pb.AggregateRequest{{Filter: &pb.Filter{Postcode: "10224", TimeRange: "1AM - 8PM",
+			Names: []string{"chicken"}, Raw: true}, Records: grpcRecords}},
+			&pb.AggregateReply{Aggregation: &pb.Aggregation{
+				UniqueRecipeCount: 3,
+				CountPerRecipe: []*pb.Aggregation_RecipeCount{{Recipe: "Cherry Balsamic Pork Chops", Count: 1},
+					{Recipe: "Creamy Chicken", Count: 1}, {Recipe: "Creamy Dill Chicken", Count: 1}},
+				BusiestPostcode: &pb.Aggregation_PostcodeCount{Postcode: "10224", DeliveryCount: 2},
+				CountPerPostcodeAndTime: &pb.Aggregation_PostcodeAndTimeCount{Postcode: "10224", From: "1AM",
+					To: "8PM", DeliveryCount: 2},
+				MatchByName: []string{"Creamy Chicken", "Creamy Dill Chicken"},
+				MatchByNameDetails: []*pb.Aggregation_NameMatch{
+					{Recipe: "Creamy Chicken", Score: 1, Terms: []string{"chicken"}, DeliveryCount: 1},
+					{Recipe: "Creamy Dill Chicken", Score: 1, Terms: []string{"chicken"}, DeliveryCount: 1}},
+				MatchByNameDeliveryCount: 2,
+			}, Parsed: 3, Ignored: 1, Final: true}, codes.OK},
+		{"Group by", []*pb.AggregateRequest{{Filter: &pb.Filter{GroupBy: []string{"postcode"}}, Records: grpcRecords}},
+			&pb.AggregateReply{Aggregation: &pb.Aggregation{
+				UniqueRecipeCount: 2,
+				CountPerRecipe: []*pb.Aggregation_RecipeCount{{Recipe: "Cherry Balsamic Pork Chops", Count: 1},
+					{Recipe: "Creamy Dill Chicken", Count: 2}},
+				BusiestPostcode: &pb.Aggregation_PostcodeCount{Postcode: "10224", DeliveryCount: 2},
+				CountPerPostcodeAndTime: &pb.Aggregation_PostcodeAndTimeCount{Postcode: "10120", From: "10AM",
+					To: "3PM", DeliveryCount: 1},
+				CountPerGroup: []*pb.Aggregation_GroupCount{
+					{Group: map[string]string{"postcode": "10224"}, DeliveryCount: 2},
+					{Group: map[string]string{"postcode": "10120"}, DeliveryCount: 1}},
+			}, Parsed: 3, Ignored: 1, Final: true}, codes.OK},
+		{"Invalid filter", []*pb.AggregateRequest{{Filter: &pb.Filter{MatchMode: "soundex"}}}, nil,
+			codes.InvalidArgument},
+		{"Invalid group by", []*pb.AggregateRequest{{Filter: &pb.Filter{GroupBy: []string{"box_size"}}}}, nil,
+			codes.InvalidArgument},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stream, err := client.Aggregate(context.Background())
+			if err != nil {
+				t.Fatalf("%s, unexpected error: %v", c.name, err)
+			}
+			for _, req := range c.reqs {
+				stream.Send(req)
+			}
+
+			got, err := stream.CloseAndRecv()
+			if status.Code(err) != c.wantCode || !proto.Equal(c.want, got) {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestGRPCWatchAggregate(t *testing.T) {
+	client := newGRPCClient(t)
+	stream, err := client.WatchAggregate(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stream.Send(&pb.AggregateRequest{Records: grpcRecords[:2], PartialIntervalMs: 1})
+	for {
+		partial, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if partial.GetFinal() {
+			t.Fatalf("want a partial aggregation, got: %v", partial)
+		}
+		if partial.GetParsed() == 2 {
+			break
+		}
+	}
+
+	stream.Send(&pb.AggregateRequest{Records: grpcRecords[2:]})
+	stream.CloseSend()
+	var got *pb.AggregateReply
+	for !got.GetFinal() {
+		if got, err = stream.Recv(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got.GetParsed() != 3 || got.GetIgnored() != 1 || got.GetAggregation().GetUniqueRecipeCount() != 2 {
+		t.Errorf("want: 3 1 2, got: %v %v %v", got.GetParsed(), got.GetIgnored(),
+			got.GetAggregation().GetUniqueRecipeCount())
+	}
+}
+
+func newGRPCClient(t *testing.T) pb.AggregatorClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterAggregatorServer(srv, NewGRPCServer(ServerConfig{
+		DefaultFilter: regularFilter,
+		Aliases:       map[string]string{"Creamy Chicken": "Creamy Dill Chicken"},
+	}))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewAggregatorClient(conn)
+}
+
+var grpcRecords = []*pb.Record{
+	{Postcode: "10224", Recipe: "Creamy Dill Chicken", Delivery: "Wednesday 1AM - 7PM"},
+	{Postcode: "10224", Recipe: "Creamy Chicken", Delivery: "Wednesday 1AM - 7PM"},
+	{Postcode: "10120", Recipe: "Cherry Balsamic Pork Chops", Delivery: "Thursday 10AM - 2PM"},
+	{Postcode: "10120", Recipe: "Cherry Balsamic Pork Chops", Delivery: ""},
+}
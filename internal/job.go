@@ -0,0 +1,370 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// States of a Job.
+const (
+	JobRunning   JobState = "running"
+	JobDone      JobState = "done"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// DefaultMaxEndedJobs is how many ended jobs a MemoryJobStore keeps by default.
+const DefaultMaxEndedJobs = 1000
+
+// ErrJobNotFound is returned when a job ID is unknown.
+var ErrJobNotFound = errors.New("job not found")
+
+type (
+	// JobState is the state of a Job. A job starts running and ends done, failed or cancelled.
+	JobState string
+	// JobStatus is the progress of a Job. Records, Parsed and Ignored are the Parse counts so far and Percent is the
+	// share of the input already read, from 0 to 100. It is 0 while running if the input size is unknown.
+	JobStatus struct {
+		ID      string   `json:"id"`
+		State   JobState `json:"state"`
+		Records int      `json:"records"`
+		Parsed  int      `json:"parsed"`
+		Ignored int      `json:"ignored"`
+		Percent float64  `json:"percent"`
+		Error   string   `json:"error,omitempty"`
+	}
+	// Job is a JobStatus plus the Aggregation of a done job.
+	Job struct {
+		JobStatus
+		Result *Aggregation `json:"result,omitempty"`
+	}
+	// JobStore keeps the jobs. Jobs are saved when they are submitted and when they end.
+	// Load returns ErrJobNotFound if the ID is unknown.
+	JobStore interface {
+		Save(job Job) error
+		Load(id string) (Job, error)
+	}
+	// MemoryJobStore is a JobStore that keeps the jobs in memory. Only the last ended jobs are kept, so it does not
+	// grow without bound: once there are more than maxEnded, the job that ended first is removed. It is safe for
+	// concurrent use.
+	MemoryJobStore struct {
+		mu       sync.RWMutex
+		jobs     map[string]Job
+		ended    []string
+		maxEnded int
+	}
+	// FileJobStore is a JobStore that keeps each job as a JSON file named after its ID in Dir, so the jobs outlive the
+	// process. A job that was running when the process stopped is loaded as failed.
+	FileJobStore struct {
+		Dir string
+	}
+	// Jobs runs aggregations in background. Each job parses its input with a SummaryCalculator, updates its
+	// JobStatus after each record and saves the Job in the JobStore when it ends. It is safe for concurrent use.
+	Jobs struct {
+		store   JobStore
+		mu      sync.Mutex
+		running map[string]*runningJob
+		wg      sync.WaitGroup
+	}
+	// runningJob has the counters of a job in progress. They are first so they are 64-bit aligned for atomic.
+	runningJob struct {
+		read, records, parsed, ignored int64
+		size                           int64
+		cancel                         context.CancelFunc
+	}
+	// progressReader counts the bytes read and fails once ctx is done, so a cancelled job stops parsing.
+	progressReader struct {
+		ctx  context.Context
+		r    io.Reader
+		read *int64
+	}
+)
+
+// NewMemoryJobStore creates an empty MemoryJobStore that keeps up to maxEnded ended jobs. A maxEnded not greater
+// than zero falls back to DefaultMaxEndedJobs.
+func NewMemoryJobStore(maxEnded int) *MemoryJobStore {
+	if maxEnded <= 0 {
+		maxEnded = DefaultMaxEndedJobs
+	}
+
+	return &MemoryJobStore{jobs: make(map[string]Job), maxEnded: maxEnded}
+}
+
+// Save stores job replacing any job with the same ID. If job ended, it removes the ended jobs beyond the maximum,
+// the ones that ended first.
+func (s *MemoryJobStore) Save(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, ok := s.jobs[job.ID]
+	s.jobs[job.ID] = job
+	if job.State == JobRunning || (ok && previous.State != JobRunning) {
+		return nil
+	}
+
+	s.ended = append(s.ended, job.ID)
+	for len(s.ended) > s.maxEnded {
+		delete(s.jobs, s.ended[0])
+		s.ended = s.ended[1:]
+	}
+
+	return nil
+}
+
+// Load returns the job with the given ID.
+func (s *MemoryJobStore) Load(id string) (Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// NewFileJobStore creates a FileJobStore creating dir if it does not exist.
+// It returns an error if dir cannot be created.
+func NewFileJobStore(dir string) (*FileJobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error to create job store [dir=%v]: %v", dir, err)
+	}
+
+	return &FileJobStore{Dir: dir}, nil
+}
+
+// Save writes job to a temporary file and renames it, so a job file is never partially written.
+func (s *FileJobStore) Save(job Job) error {
+	content, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("error to encode job [id=%v]: %v", job.ID, err)
+	}
+
+	tmp := s.path(job.ID) + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("error to write job [file=%v]: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path(job.ID)); err != nil {
+		return fmt.Errorf("error to write job [file=%v]: %v", s.path(job.ID), err)
+	}
+
+	return nil
+}
+
+// Load reads the job with the given ID.
+func (s *FileJobStore) Load(id string) (Job, error) {
+	if !isJobID(id) {
+		return Job{}, ErrJobNotFound
+	}
+
+	content, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return Job{}, ErrJobNotFound
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("error to read job [file=%v]: %v", s.path(id), err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(content, &job); err != nil {
+		return Job{}, fmt.Errorf("error to decode job [file=%v]: %v", s.path(id), err)
+	}
+
+	return job, nil
+}
+
+func (s *FileJobStore) path(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
+
+// NewJobs creates a Jobs that keeps the jobs in store.
+func NewJobs(store JobStore) *Jobs {
+	return &Jobs{store: store, running: make(map[string]*runningJob)}
+}
+
+// Submit starts a job that parses src and aggregates its records given filter and the normalizers, which might be
+// nil. size is the length of src in bytes, or 0 if it is unknown. src is closed when the job ends.
+// It returns the JobStatus of the new job and an error if the job cannot be saved.
+func (j *Jobs) Submit(src io.ReadCloser, size int64, filter Filter, recipes *RecipeNormalizer,
+	postcodes *PostcodeNormalizer) (JobStatus, error) {
+	id, err := newJobID()
+	if err != nil {
+		src.Close()
+		return JobStatus{}, err
+	}
+
+	status := JobStatus{ID: id, State: JobRunning}
+	if err := j.store.Save(Job{JobStatus: status}); err != nil {
+		src.Close()
+		return JobStatus{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rj := &runningJob{cancel: cancel, size: size}
+	j.mu.Lock()
+	j.running[id] = rj
+	j.mu.Unlock()
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		defer cancel()
+		defer src.Close()
+		j.run(ctx, id, rj, src, filter, recipes, postcodes)
+	}()
+
+	return status, nil
+}
+
+// Status returns the JobStatus of the job with the given ID.
+// It returns ErrJobNotFound if the ID is unknown.
+func (j *Jobs) Status(id string) (JobStatus, error) {
+	job, err := j.Job(id)
+
+	return job.JobStatus, err
+}
+
+// Job returns the job with the given ID. Its Result is nil until the job is done.
+// It returns ErrJobNotFound if the ID is unknown.
+func (j *Jobs) Job(id string) (Job, error) {
+	j.mu.Lock()
+	rj, ok := j.running[id]
+	j.mu.Unlock()
+	if ok {
+		return Job{JobStatus: rj.status(id, JobRunning)}, nil
+	}
+
+	job, err := j.store.Load(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.State == JobRunning {
+		job.State = JobFailed
+		job.Error = "job interrupted"
+	}
+
+	return job, nil
+}
+
+// Cancel stops the job with the given ID. The job ends cancelled unless it has already ended.
+// It returns ErrJobNotFound if the ID is unknown.
+func (j *Jobs) Cancel(id string) error {
+	j.mu.Lock()
+	rj, ok := j.running[id]
+	j.mu.Unlock()
+	if ok {
+		rj.cancel()
+		return nil
+	}
+
+	_, err := j.store.Load(id)
+
+	return err
+}
+
+// Wait waits for the running jobs to end.
+func (j *Jobs) Wait() {
+	j.wg.Wait()
+}
+
+// Close cancels the running jobs and waits for them to end.
+func (j *Jobs) Close() {
+	j.mu.Lock()
+	for _, rj := range j.running {
+		rj.cancel()
+	}
+	j.mu.Unlock()
+	j.Wait()
+}
+
+func (j *Jobs) run(ctx context.Context, id string, rj *runningJob, src io.Reader, filter Filter,
+	recipes *RecipeNormalizer, postcodes *PostcodeNormalizer) {
+	calculator := NewSummaryCalculator(filter)
+	var calc Calculator = &calculator
+	if recipes != nil {
+		calc = NewNormalizingCalculator(calc, recipes, postcodes)
+	}
+
+	_, _, err := parseRecords(ctx, &progressReader{ctx, src, &rj.read}, DefaultSchema, calc, func(rc, pc, ic int) {
+		atomic.StoreInt64(&rj.records, int64(rc))
+		atomic.StoreInt64(&rj.parsed, int64(pc))
+		atomic.StoreInt64(&rj.ignored, int64(ic))
+	})
+
+	job := Job{JobStatus: rj.status(id, JobDone)}
+	switch {
+	case err != nil && ctx.Err() != nil:
+		job.State = JobCancelled
+	case err != nil:
+		job.State = JobFailed
+		job.Error = err.Error()
+	default:
+		job.Percent = 100
+		aggregation := calculator.Aggregate()
+		job.Result = &aggregation
+	}
+	if err := j.store.Save(job); err != nil {
+		job.State = JobFailed
+		job.Error = err.Error()
+		job.Result = nil
+		j.store.Save(job)
+	}
+
+	j.mu.Lock()
+	delete(j.running, id)
+	j.mu.Unlock()
+}
+
+func (rj *runningJob) status(id string, state JobState) JobStatus {
+	status := JobStatus{
+		ID:      id,
+		State:   state,
+		Records: int(atomic.LoadInt64(&rj.records)),
+		Parsed:  int(atomic.LoadInt64(&rj.parsed)),
+		Ignored: int(atomic.LoadInt64(&rj.ignored)),
+	}
+	if rj.size > 0 {
+		percent := float64(atomic.LoadInt64(&rj.read)) * 100 / float64(rj.size)
+		status.Percent = math.Min(math.Round(percent*100)/100, 100)
+	}
+
+	return status
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := p.r.Read(b)
+	atomic.AddInt64(p.read, int64(n))
+
+	return n, err
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error to generate job id: %v", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func isJobID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+
+	return err == nil
+}
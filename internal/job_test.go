@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobs(t *testing.T) {
+	cases := []struct {
+		name      string
+		in        string
+		wantState JobState
+		wantError bool
+	}{
+		{"Done", serverArrayBody, JobDone, false},
+		{"NDJSON", serverNDJSONBody, JobDone, false},
+		{"Failed", `[{"postcode": 10120}]`, JobFailed, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			jobs := NewJobs(NewMemoryJobStore(0))
+			status, err := jobs.Submit(ioutil.NopCloser(strings.NewReader(c.in)), int64(len(c.in)), regularFilter,
+				nil, nil)
+			if err != nil || status.State != JobRunning {
+				t.Fatalf("%s, want: %v, got: %v %v", c.name, JobRunning, status.State, err)
+			}
+			jobs.Wait()
+
+			got, err := jobs.Job(status.ID)
+			if err != nil || got.State != c.wantState || (got.Error != "") != c.wantError {
+				t.Fatalf("%s, want: %v %v, got: %v %v %v", c.name, c.wantState, c.wantError, got.State, got.Error, err)
+			}
+			if c.wantState != JobDone {
+				return
+			}
+
+			calculator := NewSummaryCalculator(regularFilter)
+			parsed, ignored, _ := ParseReader(strings.NewReader(c.in), &calculator, false)
+			want := JobStatus{ID: status.ID, State: JobDone, Records: parsed + ignored, Parsed: parsed, Ignored: ignored,
+				Percent: 100}
+			if got.JobStatus != want || !reflect.DeepEqual(*got.Result, calculator.Aggregate()) {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, want, calculator.Aggregate(), got.JobStatus, got.Result)
+			}
+		})
+	}
+}
+
+func TestJobsCancel(t *testing.T) {
+	jobs := NewJobs(NewMemoryJobStore(0))
+	r, w := io.Pipe()
+	status, _ := jobs.Submit(r, 0, regularFilter, nil, nil)
+
+	w.Write([]byte(`[{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"},`))
+	waitJob(t, jobs, status.ID, func(s JobStatus) bool { return s.Records == 1 })
+
+	if err := jobs.Cancel(status.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.Close()
+	got := waitJob(t, jobs, status.ID, func(s JobStatus) bool { return s.State != JobRunning })
+
+	if got.State != JobCancelled || got.Parsed != 1 {
+		t.Errorf("want: %v 1, got: %v %v", JobCancelled, got.State, got.Parsed)
+	}
+	if err := jobs.Cancel("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("want: %v, got: %v", ErrJobNotFound, err)
+	}
+}
+
+func TestMemoryJobStoreMaxEnded(t *testing.T) {
+	s := NewMemoryJobStore(2)
+	for _, job := range []Job{
+		{JobStatus: JobStatus{ID: "a", State: JobRunning}},
+		{JobStatus: JobStatus{ID: "b", State: JobRunning}},
+		{JobStatus: JobStatus{ID: "a", State: JobDone}},
+		{JobStatus: JobStatus{ID: "c", State: JobFailed}},
+		{JobStatus: JobStatus{ID: "c", State: JobFailed, Error: "saved again"}},
+		{JobStatus: JobStatus{ID: "d", State: JobCancelled}},
+	} {
+		s.Save(job)
+	}
+
+	for id, want := range map[string]error{"a": ErrJobNotFound, "b": nil, "c": nil, "d": nil} {
+		if _, err := s.Load(id); err != want {
+			t.Errorf("%s, want: %v, got: %v", id, want, err)
+		}
+	}
+}
+
+func TestFileJobStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, _ := NewFileJobStore(dir)
+	jobs := NewJobs(store)
+	status, _ := jobs.Submit(ioutil.NopCloser(strings.NewReader(serverArrayBody)), 0, regularFilter, nil, nil)
+	jobs.Wait()
+	want, _ := jobs.Job(status.ID)
+
+	interrupted := Job{JobStatus: JobStatus{ID: strings.Repeat("a", 32), State: JobRunning, Records: 7}}
+	store.Save(interrupted)
+
+	cases := []struct {
+		name    string
+		id      string
+		want    Job
+		wantErr error
+	}{
+		{"Done", status.ID, want, nil},
+		{"Interrupted", interrupted.ID, Job{JobStatus: JobStatus{ID: interrupted.ID, State: JobFailed, Records: 7,
+			Error: "job interrupted"}}, nil},
+		{"Unknown", strings.Repeat("b", 32), Job{}, ErrJobNotFound},
+		{"Invalid id", "../jobs", Job{}, ErrJobNotFound},
+	}
+
+	restarted := NewJobs(store)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := restarted.Job(c.id)
+
+			if !reflect.DeepEqual(c.want, got) || !errors.Is(err, c.wantErr) {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantErr, got, err)
+			}
+		})
+	}
+}
+
+func waitJob(t *testing.T, jobs *Jobs, id string, done func(JobStatus) bool) JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := jobs.Status(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if done(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for job %s, got: %v", id, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
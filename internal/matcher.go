@@ -0,0 +1,246 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MatchMode selects how each Filter.Recipes term is compared against the recipe names.
+type MatchMode string
+
+const (
+	// SubstringMatch matches recipe names that contain the term, ignoring case. It is the default mode.
+	SubstringMatch MatchMode = "substring"
+	// WordMatch matches recipe names that contain the term as whole words, ignoring case.
+	// E.g. "veggie" matches "Grilled Cheese and Veggie Jumble" but not "Mediterranean Baked Veggies".
+	WordMatch MatchMode = "word"
+	// PrefixMatch matches recipe names that start with the term, ignoring case.
+	PrefixMatch MatchMode = "prefix"
+	// RegexMatch treats the term as a case-insensitive regular expression.
+	RegexMatch MatchMode = "regex"
+	// GlobMatch treats the term as a case-insensitive glob pattern (*, ? and [...]) over the whole recipe name.
+	GlobMatch MatchMode = "glob"
+	// FuzzyMatch compares the term against every sequence of words of the recipe name with the same number of
+	// words using the Levenshtein distance. Names whose similarity reaches Filter.Threshold match.
+	FuzzyMatch MatchMode = "fuzzy"
+)
+
+// DefaultFuzzyThreshold is the default Filter.Threshold of the threshold flag and of the server filters.
+const DefaultFuzzyThreshold = 0.8
+
+// MatchModes lists all supported match modes.
+var MatchModes = []MatchMode{SubstringMatch, WordMatch, PrefixMatch, RegexMatch, GlobMatch, FuzzyMatch}
+
+// nameMatcher compares a recipe name against a single filter term. It receives the name and its lower case version,
+// so the caller lowers it only once for all terms. It returns a similarity score between 0 and 1 and whether the name
+// matches. All modes but FuzzyMatch score a match as 1.
+type nameMatcher interface {
+	match(recipe, lowerRecipe string) (float64, bool)
+}
+
+type (
+	substringMatcher struct{ term string }
+	wordMatcher      struct{ words []string }
+	prefixMatcher    struct{ term string }
+	regexMatcher     struct{ re *regexp.Regexp }
+	fuzzyMatcher     struct {
+		term      string
+		words     int
+		threshold float64
+	}
+)
+
+// newNameMatcher creates a nameMatcher for term according mode. An empty mode falls back to SubstringMatch.
+// It returns an error if the mode is unknown, the threshold is out of (0, 1] in FuzzyMatch, where it has no default,
+// or the term is an invalid pattern.
+func newNameMatcher(mode MatchMode, term string, threshold float64) (nameMatcher, error) {
+	if mode == FuzzyMatch && (threshold <= 0 || threshold > 1) {
+		return nil, fmt.Errorf("threshold %v must be greater than 0 and at most 1", threshold)
+	}
+
+	lowerTerm := strings.ToLower(term)
+	switch mode {
+	case "", SubstringMatch:
+		return substringMatcher{lowerTerm}, nil
+	case WordMatch:
+		return wordMatcher{words(lowerTerm)}, nil
+	case PrefixMatch:
+		return prefixMatcher{lowerTerm}, nil
+	case RegexMatch:
+		re, err := regexp.Compile("(?i)" + term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", term, err)
+		}
+		return regexMatcher{re}, nil
+	case GlobMatch:
+		re, err := globToRegexp(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %v", term, err)
+		}
+		return regexMatcher{re}, nil
+	case FuzzyMatch:
+		ws := words(lowerTerm)
+		return fuzzyMatcher{strings.Join(ws, " "), len(ws), threshold}, nil
+	}
+
+	return nil, fmt.Errorf("unknown match mode %q, supported modes: %v", mode, MatchModes)
+}
+
+func (m substringMatcher) match(_, lowerRecipe string) (float64, bool) {
+	return boolScore(strings.Contains(lowerRecipe, m.term))
+}
+
+func (m wordMatcher) match(_, lowerRecipe string) (float64, bool) {
+	if len(m.words) == 0 {
+		return boolScore(false)
+	}
+
+	rws := words(lowerRecipe)
+	for i := 0; i+len(m.words) <= len(rws); i++ {
+		if equalWords(rws[i:i+len(m.words)], m.words) {
+			return boolScore(true)
+		}
+	}
+
+	return boolScore(false)
+}
+
+func (m prefixMatcher) match(_, lowerRecipe string) (float64, bool) {
+	return boolScore(strings.HasPrefix(lowerRecipe, m.term))
+}
+
+func (m regexMatcher) match(recipe, _ string) (float64, bool) {
+	return boolScore(m.re.MatchString(recipe))
+}
+
+func (m fuzzyMatcher) match(_, lowerRecipe string) (float64, bool) {
+	if m.words == 0 {
+		return 0, false
+	}
+
+	best := 0.0
+	rws := words(lowerRecipe)
+	for i := 0; i+m.words <= len(rws); i++ {
+		if s := similarity(m.term, strings.Join(rws[i:i+m.words], " ")); s > best {
+			best = s
+		}
+	}
+
+	best = math.Round(best*100) / 100
+	return best, best >= m.threshold
+}
+
+func boolScore(ok bool) (float64, bool) {
+	if ok {
+		return 1, true
+	}
+
+	return 0, false
+}
+
+// words splits s into words. Any character that is not a letter, a digit or an apostrophe is a separator.
+// E.g. "Stovetop Mac 'N' Cheese" becomes ["Stovetop", "Mac", "'N'", "Cheese"].
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// similarity returns 1 minus the Levenshtein distance between a and b normalized by the length of the longest one.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// globToRegexp converts a glob pattern into an anchored case-insensitive regexp. It supports "*" (any sequence of
+// characters), "?" (any single character) and "[...]" character classes.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	rs := []rune(glob)
+	for i := 0; i < len(rs); i++ {
+		switch c := rs[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '[':
+			end := indexRune(rs[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := string(rs[i+1 : i+end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+func indexRune(rs []rune, r rune) int {
+	for i, c := range rs {
+		if c == r {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameMatcher(t *testing.T) {
+	cases := []struct {
+		name      string
+		mode      MatchMode
+		term      string
+		recipe    string
+		wantScore float64
+		wantOk    bool
+	}{
+		{"Substring - YES", SubstringMatch, "veggie", "Mediterranean Baked Veggies", 1, true},
+		{"Substring - NO", SubstringMatch, "Mushrom", "American One-Pan Mushroom", 0, false},
+		{"Default mode is substring", "", "VEGGIE", "Grilled Cheese and Veggie Jumble", 1, true},
+		{"Word - YES", WordMatch, "veggie jumble", "Grilled Cheese and Veggie Jumble", 1, true},
+		{"Word - NO", WordMatch, "veggie", "Mediterranean Baked Veggies", 0, false},
+		{"Word - hyphen splits words", WordMatch, "pan", "American One-Pan Mushroom", 1, true},
+		{"Prefix - YES", PrefixMatch, "tex-mex", "Tex-Mex Tilapia", 1, true},
+		{"Prefix - NO", PrefixMatch, "tilapia", "Tex-Mex Tilapia", 0, false},
+		{"Regex - YES", RegexMatch, `^(hot|honey)\b`, "Honey Sesame Chicken", 1, true},
+		{"Regex - NO", RegexMatch, `chicken$`, "Chicken Sausage Pizzas", 0, false},
+		{"Glob - YES", GlobMatch, "*pork ch?ps", "Cherry Balsamic Pork Chops", 1, true},
+		{"Glob - class", GlobMatch, "[!a]*mushroom", "American One-Pan Mushroom", 0, false},
+		{"Glob - NO", GlobMatch, "pork*", "Cherry Balsamic Pork Chops", 0, false},
+		{"Fuzzy - typo", FuzzyMatch, "Mushrom", "American One-Pan Mushroom", 0.88, true},
+		{"Fuzzy - plural", FuzzyMatch, "mushrooms", "American One-Pan Mushroom", 0.89, true},
+		{"Fuzzy - multiple words", FuzzyMatch, "pork chilli", "Hearty Pork Chili", 0.91, true},
+		{"Fuzzy - below threshold", FuzzyMatch, "Potato", "Stovetop Mac 'N' Cheese", 0.38, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := newNameMatcher(c.mode, c.term, DefaultFuzzyThreshold)
+			if err != nil {
+				t.Fatalf("%s, unexpected error: %v", c.name, err)
+			}
+
+			if gotScore, gotOk := m.match(c.recipe, strings.ToLower(c.recipe)); gotScore != c.wantScore || gotOk != c.wantOk {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.wantScore, c.wantOk, gotScore, gotOk)
+			}
+		})
+	}
+}
+
+func TestNewNameMatcherErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		mode      MatchMode
+		term      string
+		threshold float64
+	}{
+		{"Unknown mode", "soundex", "Veggie", 0},
+		{"Invalid regex", RegexMatch, "Veggie(", 0},
+		{"Invalid glob", GlobMatch, "[Veggie", 0},
+		{"Threshold above one", FuzzyMatch, "Veggie", 1.5},
+		{"Negative threshold", FuzzyMatch, "Veggie", -0.1},
+		{"Zero threshold", FuzzyMatch, "Veggie", 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := newNameMatcher(c.mode, c.term, c.threshold); err == nil {
+				t.Errorf("%s, want an error, got: nil", c.name)
+			}
+		})
+	}
+}
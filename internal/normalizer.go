@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+type (
+	// RecipeNormalizer maps the spelling variants of a recipe name to a single name. A name is normalized trimming
+	// it, collapsing its inner whitespaces into one space and applying the Unicode NFC. Names that are equal after
+	// case folding are the same recipe and the first spelling seen is kept. Aliases map variant names to canonical
+	// ones after the same normalization, so "Tex Mex Tilapia" might become "Tex-Mex Tilapia".
+	// It keeps a cache of every raw name seen, so it MUST NOT be used in concurrent environments without proper
+	// synchronization.
+	RecipeNormalizer struct {
+		aliases map[string]string
+		names   map[string]string
+		cache   map[string]string
+		fold    cases.Caser
+	}
+	// PostcodeNormalizer normalizes postcodes and optionally rolls them up into groups. A postcode is normalized
+	// removing its whitespaces, upper casing it and removing the leading zeros of numeric postcodes, so " 10120" and
+	// "010120" become "10120". Then, if the normalized postcode is in the regions map it becomes its region, otherwise
+	// if prefix is greater than zero it becomes its first prefix characters. E.g. prefix 3: "10120" becomes "101".
+	// It keeps a cache of every raw postcode seen, so it MUST NOT be used in concurrent environments without proper
+	// synchronization.
+	PostcodeNormalizer struct {
+		prefix  int
+		regions map[string]string
+		cache   map[string]string
+	}
+	// NormalizingCalculator normalizes Record.Recipe and Record.Postcode before delegating the Record to the wrapped
+	// Calculator. A nil normalizer skips its field.
+	NormalizingCalculator struct {
+		Calculator
+		Recipes   *RecipeNormalizer
+		Postcodes *PostcodeNormalizer
+	}
+)
+
+// NewRecipeNormalizer creates a RecipeNormalizer given an alias map of variant names to canonical names. The alias
+// map might be nil.
+func NewRecipeNormalizer(aliases map[string]string) *RecipeNormalizer {
+	n := &RecipeNormalizer{
+		aliases: make(map[string]string, len(aliases)),
+		names:   make(map[string]string),
+		cache:   make(map[string]string),
+		fold:    cases.Fold(),
+	}
+	for variant, canonical := range aliases {
+		n.aliases[n.key(clean(variant))] = clean(canonical)
+	}
+
+	return n
+}
+
+// LoadAliases reads an alias file. It is a JSON object whose keys are variant names and values are canonical names.
+// E.g. {"Tex Mex Tilapia": "Tex-Mex Tilapia"}.
+// It returns an error if the file cannot be read or decoded.
+func LoadAliases(filepath string) (map[string]string, error) {
+	return loadStringMap(filepath, "aliases")
+}
+
+// Normalize returns the canonical name of recipe.
+func (n *RecipeNormalizer) Normalize(recipe string) string {
+	if name, ok := n.cache[recipe]; ok {
+		return name
+	}
+
+	cleaned := clean(recipe)
+	key := n.key(cleaned)
+	name, ok := n.aliases[key]
+	if !ok {
+		if name, ok = n.names[key]; !ok {
+			name = cleaned
+			n.names[key] = name
+		}
+	}
+	n.cache[recipe] = name
+
+	return name
+}
+
+// Names returns the first spelling seen of each recipe, sorted. A RecipeNormalizer restored with AddNames keeps
+// them, so a later run spells the recipes as this one did.
+func (n *RecipeNormalizer) Names() []string {
+	names := make([]string, 0, len(n.names))
+	for _, name := range n.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// AddNames adds names as the first spellings seen of their recipes, unless a spelling of the same recipe was seen
+// already.
+func (n *RecipeNormalizer) AddNames(names []string) {
+	for _, name := range names {
+		cleaned := clean(name)
+		if key := n.key(cleaned); n.names[key] == "" {
+			n.names[key] = cleaned
+		}
+	}
+}
+
+// NewPostcodeNormalizer creates a PostcodeNormalizer given the prefix length used to group postcodes and a map of
+// postcodes to regions. A zero prefix disables the prefix grouping and the regions map might be nil.
+func NewPostcodeNormalizer(prefix int, regions map[string]string) *PostcodeNormalizer {
+	p := &PostcodeNormalizer{
+		prefix:  prefix,
+		regions: make(map[string]string, len(regions)),
+		cache:   make(map[string]string),
+	}
+	for postcode, region := range regions {
+		p.regions[normalizePostcode(postcode)] = region
+	}
+
+	return p
+}
+
+// ParsePostcodeGroup parses a postcode group in the format "prefix:N" and returns N.
+// It returns an error if the format is invalid or N is not a positive number.
+func ParsePostcodeGroup(group string) (int, error) {
+	const prefix = "prefix:"
+	if !strings.HasPrefix(group, prefix) {
+		return 0, fmt.Errorf("invalid postcode group %q, want the format prefix:N", group)
+	}
+
+	n, err := strconv.Atoi(group[len(prefix):])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid postcode group %q, N must be a positive number", group)
+	}
+
+	return n, nil
+}
+
+// LoadRegions reads a region file. It is a JSON object whose keys are postcodes and values are regions.
+// E.g. {"10120": "Center", "10121": "Center"}.
+// It returns an error if the file cannot be read or decoded.
+func LoadRegions(filepath string) (map[string]string, error) {
+	return loadStringMap(filepath, "regions")
+}
+
+// Normalize returns the normalized postcode or its group.
+func (p *PostcodeNormalizer) Normalize(postcode string) string {
+	if group, ok := p.cache[postcode]; ok {
+		return group
+	}
+
+	group := normalizePostcode(postcode)
+	if region, ok := p.regions[group]; ok {
+		group = region
+	} else if p.prefix > 0 && len(group) > p.prefix {
+		group = group[:p.prefix]
+	}
+	p.cache[postcode] = group
+
+	return group
+}
+
+// NormalizeWhere normalizes the postcodes compared by =, != and IN in where, a condition of ParseWhere, as Normalize
+// does with the postcodes of the records, so "postcode = 010120" matches them. The other operators compare the
+// normalized postcodes to their values as they are written.
+func (p *PostcodeNormalizer) NormalizeWhere(where string) string {
+	return normalizeWhere(where, p.Normalize)
+}
+
+// NewNormalizingCalculator creates a NormalizingCalculator that delegates to calc. Any normalizer might be nil.
+func NewNormalizingCalculator(calc Calculator, recipes *RecipeNormalizer, postcodes *PostcodeNormalizer) NormalizingCalculator {
+	return NormalizingCalculator{calc, recipes, postcodes}
+}
+
+// Calculate normalizes r.Recipe and r.Postcode and delegates r to the wrapped Calculator.
+func (n NormalizingCalculator) Calculate(r Record) {
+	if n.Recipes != nil {
+		r.Recipe = n.Recipes.Normalize(r.Recipe)
+	}
+	if n.Postcodes != nil {
+		r.Postcode = n.Postcodes.Normalize(r.Postcode)
+	}
+	n.Calculator.Calculate(r)
+}
+
+func (n *RecipeNormalizer) key(name string) string {
+	return n.fold.String(name)
+}
+
+// clean trims name, collapses its whitespaces and applies the Unicode NFC.
+func clean(name string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(name), " "))
+}
+
+func normalizePostcode(postcode string) string {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(postcode), ""))
+	if trimmed := strings.TrimLeft(normalized, "0"); trimmed != "" && isNumeric(trimmed) {
+		return trimmed
+	}
+
+	return normalized
+}
+
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func loadStringMap(filepath, name string) (map[string]string, error) {
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("error to read %s [file=%v]: %v", name, filepath, err)
+	}
+
+	var m map[string]string
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("error to decode %s [file=%v]: %v", name, filepath, err)
+	}
+
+	return m, nil
+}
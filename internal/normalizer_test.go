@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	aliases := map[string]string{
+		"tex mex  tilapia":  "Tex-Mex Tilapia",
+		"Mac and Cheese":    "Stovetop Mac 'N' Cheese",
+		"Meatloaf a la mom": "Meatloaf à La Mom",
+	}
+	cases := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"Unchanged", []string{"Tex-Mex Tilapia"}, []string{"Tex-Mex Tilapia"}},
+		{"Whitespaces", []string{"  Tex-Mex \t Tilapia \n"}, []string{"Tex-Mex Tilapia"}},
+		{"Case folding keeps first spelling", []string{"Creamy Dill Chicken", "CREAMY dill chicken "},
+			[]string{"Creamy Dill Chicken", "Creamy Dill Chicken"}},
+		{"Unicode NFC", []string{"Meatloaf \u00e0 La Mom", "Meatloaf a\u0300 La Mom"},
+			[]string{"Meatloaf à La Mom", "Meatloaf à La Mom"}},
+		{"Aliases", []string{"Tex Mex Tilapia", "mac AND cheese", "Meatloaf A La Mom"},
+			[]string{"Tex-Mex Tilapia", "Stovetop Mac 'N' Cheese", "Meatloaf à La Mom"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := NewRecipeNormalizer(aliases)
+			var got []string
+			for _, in := range c.in {
+				got = append(got, n.Normalize(in))
+			}
+
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %q, got: %q", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizingCalculator(t *testing.T) {
+	mc := mockCalculator{}
+	calc := NewNormalizingCalculator(&mc, NewRecipeNormalizer(nil), NewPostcodeNormalizer(3, nil))
+	want := []Record{
+		{Postcode: "102", Recipe: "Tex-Mex Tilapia", Delivery: "Wednesday 1AM - 7PM"},
+		{Postcode: "102", Recipe: "Tex-Mex Tilapia", Delivery: "Thursday 7AM - 5PM"},
+	}
+
+	calc.Calculate(Record{Postcode: "10224", Recipe: "Tex-Mex Tilapia", Delivery: "Wednesday 1AM - 7PM"})
+	calc.Calculate(Record{Postcode: " 010208", Recipe: "tex-mex  tilapia ", Delivery: "Thursday 7AM - 5PM"})
+
+	if !reflect.DeepEqual(want, mc.results) {
+		t.Errorf("Error at NormalizingCalculator, want: %v, got: %v", want, mc.results)
+	}
+}
+
+func TestRecipeNormalizerNames(t *testing.T) {
+	n := NewRecipeNormalizer(nil)
+	n.Normalize("Tex-Mex Tilapia")
+	restored := NewRecipeNormalizer(nil)
+	restored.AddNames(append(n.Names(), "tex-mex tilapia", " Creamy  Chicken"))
+
+	if got := restored.Normalize("TEX-MEX TILAPIA"); got != "Tex-Mex Tilapia" {
+		t.Errorf("Normalize, want: %v, got: %v", "Tex-Mex Tilapia", got)
+	}
+	if want, got := []string{"Creamy Chicken", "Tex-Mex Tilapia"}, restored.Names(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Names, want: %v, got: %v", want, got)
+	}
+}
+
+func TestPostcodeNormalize(t *testing.T) {
+	regions := map[string]string{"010130": "Center", "10131": "Center"}
+	cases := []struct {
+		name   string
+		prefix int
+		in     string
+		want   string
+	}{
+		{"Unchanged", 0, "10120", "10120"},
+		{"Whitespaces", 0, " 10 120\t", "10120"},
+		{"Leading zeros", 0, "0010120", "10120"},
+		{"Only zeros", 0, "000", "000"},
+		{"Alphanumeric", 0, " sw1a 1aa", "SW1A1AA"},
+		{"Alphanumeric leading zero", 0, "0X1", "0X1"},
+		{"Prefix", 3, "010186", "101"},
+		{"Prefix longer than postcode", 6, "10186", "10186"},
+		{"Region", 3, "10130", "Center"},
+		{"Region after normalization", 0, " 010131", "Center"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := NewPostcodeNormalizer(c.prefix, regions).Normalize(c.in); got != c.want {
+				t.Errorf("%s, want: %q, got: %q", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestParsePostcodeGroup(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"Valid", "prefix:3", 3, false},
+		{"Unknown group", "suffix:3", 0, true},
+		{"Not a number", "prefix:three", 0, true},
+		{"Zero", "prefix:0", 0, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got, err := ParsePostcodeGroup(c.in); got != c.want || (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantErr, got, err)
+			}
+		})
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	createFile(`{"Tex Mex Tilapia": "Tex-Mex Tilapia"}`)
+	defer removeFile()
+	want := map[string]string{"Tex Mex Tilapia": "Tex-Mex Tilapia"}
+
+	got, err := LoadAliases(stubFile)
+
+	if err != nil || !reflect.DeepEqual(want, got) {
+		t.Errorf("Error at LoadAliases, want: %v, got: %v, err: %v", want, got, err)
+	}
+}
+
+func TestLoadAliasesErrors(t *testing.T) {
+	createFile(`["Tex Mex Tilapia"]`)
+	defer removeFile()
+
+	if _, err := LoadAliases(stubFile); err == nil {
+		t.Errorf("Error at LoadAliases, want an error for an invalid file, got: nil")
+	}
+	if _, err := LoadAliases(stubFile + ".missing"); err == nil {
+		t.Errorf("Error at LoadAliases, want an error for a missing file, got: nil")
+	}
+}
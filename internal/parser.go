@@ -2,27 +2,30 @@ package internal
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"regexp"
+	"strings"
 )
 
 // ConsoleClear is a constant that "cleans" the console. It is used only in verbose mode for debug purposes.
 // It was tested in a Linux environment.
 const ConsoleClear = "\033[H\033[2J"
 
+var (
+	beginHourRegex = regexp.MustCompile(`(1[0-2]|0?[1-9])(?:[Aa][Mm])`)
+	endHourRegex   = regexp.MustCompile(`(1[0-2]|0?[1-9])(?:[Pp][Mm])`)
+)
+
 // GetBeginHourStr returns a slice with the matched begin hour.
 // E.g. "10AM - 3PM":  [0] == "10AM, [1] == "10"
-// It returns an error if regexp.Compile fails or re.FindStringSubmatch does not found anything.
+// It returns an error if re.FindStringSubmatch does not found anything.
 func GetBeginHourStr(timeRange string) ([]string, error) {
-	re, err := regexp.Compile(`(1[0-2]|0?[1-9])(?:[Aa][Mm])`)
-	if err != nil {
-		return nil, err
-	}
-
-	matches := re.FindStringSubmatch(timeRange)
+	matches := beginHourRegex.FindStringSubmatch(timeRange)
 	if len(matches) <= 0 {
 		return nil, fmt.Errorf("begin hour not found at %s", timeRange)
 	}
@@ -32,14 +35,9 @@ func GetBeginHourStr(timeRange string) ([]string, error) {
 
 // GetEndHourStr returns a slice with the matched end hour.
 // E.g. "10AM - 3PM":  [0] == "3PM, [0] == "3"
-// It returns an error if regexp.Compile fails or re.FindStringSubmatch does not found anything.
+// It returns an error if re.FindStringSubmatch does not found anything.
 func GetEndHourStr(timeRange string) ([]string, error) {
-	re, err := regexp.Compile(`(1[0-2]|0?[1-9])(?:[Pp][Mm])`)
-	if err != nil {
-		return nil, err
-	}
-
-	matches := re.FindStringSubmatch(timeRange)
+	matches := endHourRegex.FindStringSubmatch(timeRange)
 	if len(matches) <= 0 {
 		return nil, fmt.Errorf("end hour not found at %s", timeRange)
 	}
@@ -48,49 +46,196 @@ func GetEndHourStr(timeRange string) ([]string, error) {
 }
 
 // Parse opens a file given filepath, decodes it and apply Calculator.calculate() for each parsed record.
-// It panics if any parse error happens. For instance: an invalid JSON.
+// It exits if any parse error happens. For instance: an invalid JSON.
 // It returns parsed and ignored:
 // - parsed is a count with all successful parsed records;
 // - ignored contains all invalid records that were ignored;
 func Parse(filepath string, calc Calculator, isVerbose bool) (parsed int, ignored int) {
+	parsed, ignored, err := ParseContext(context.Background(), filepath, DefaultSchema, calc, isVerbose)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return
+}
+
+// ParseContext is Parse decoding the records with schema and stopping before the next record once ctx is done. Then it
+// returns the counts of the records calculated so far and ctx.Err().
+// It returns an error if the file cannot be read or parsed instead of exiting.
+func ParseContext(ctx context.Context, filepath string, schema Schema, calc Calculator, isVerbose bool) (parsed int,
+	ignored int, err error) {
 	f, err := os.Open(filepath)
 	if err != nil {
-		log.Fatalf("Error to read [file=%v]: %v", filepath, err.Error())
+		return 0, 0, fmt.Errorf("error to read [file=%v]: %v", filepath, err.Error())
 	}
+	defer f.Close()
 
-	r := bufio.NewReader(f)
-	d := json.NewDecoder(r)
+	parsed, ignored, err = parseRecords(ctx, f, schema, calc, func(rc, pc, ic int) {
+		logCount(isVerbose, rc, pc, ic)
+	})
+	if err != nil && err != ctx.Err() {
+		return parsed, ignored, fmt.Errorf("error to parse [file=%v]: %v", filepath, err.Error())
+	}
+
+	return parsed, ignored, err
+}
+
+// ParseReader decodes the records read from r and apply Calculator.calculate() for each valid record. The input is
+// either a JSON array of records, a stream of records, such as NDJSON (one record per line), or a columnar file written
+// by Dataset.WriteColumnar, detected by its header.
+// It returns the same counts as Parse and an error if the input is not a valid JSON. Records decoded before the
+// error are already calculated.
+func ParseReader(r io.Reader, calc Calculator, isVerbose bool) (parsed int, ignored int, err error) {
+	return parseRecords(context.Background(), r, DefaultSchema, calc, func(rc, pc, ic int) {
+		logCount(isVerbose, rc, pc, ic)
+	})
+}
+
+// parseRecords is ParseReader decoding the records with schema and calling progress with the records, parsed and
+// ignored counts after each record. It stops before the next record once ctx is done and returns ctx.Err().
+func parseRecords(ctx context.Context, r io.Reader, schema Schema, calc Calculator, progress func(rc, pc, ic int)) (
+	parsed int, ignored int, err error) {
+	br := bufio.NewReader(r)
+	if isColumnar(br) {
+		return decodeColumnar(ctx, br, schema, calc, progress)
+	}
 
-	nextToken(d)
-	parsed = 0
-	ignored = 0
-	i := 0
-	for d.More() {
+	err = decodeRecordsFrom(br, schema, nil, func(p recordPosition, r *Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if r.IsValid() {
+			calc.Calculate(*r)
+			parsed++
+		} else {
+			ignored++
+		}
+		progress(p.Index, parsed, ignored)
+		return nil
+	})
+
+	return parsed, ignored, err
+}
+
+// recordPosition is the position of a record in the input. Offset is the byte right after the record, Index is the
+// index of the record starting at 1 and Array tells if the input is a JSON array.
+type recordPosition struct {
+	Offset int64
+	Index  int
+	Array  bool
+}
+
+// decodeRecords decodes the records read from r, a JSON array or a stream of records, with schema and calls fn with the
+// index (starting at 1) of each record and the record with its delivery already parsed.
+// It returns an error if the input is not a valid JSON or a value of schema is neither a string nor a number.
+func decodeRecords(r io.Reader, schema Schema, fn func(i int, r *Record)) error {
+	return decodeRecordsFrom(r, schema, nil, func(p recordPosition, r *Record) error {
+		fn(p.Index, r)
+		return nil
+	})
+}
+
+// decodeRecordsFrom is decodeRecords calling fn with the position of each record. If from is not nil, r is the input
+// after the record at from, so it goes on decoding the records that follow it. It stops at the first error of fn and
+// returns it. A columnar file is rejected, as it has no positions to resume from.
+func decodeRecordsFrom(r io.Reader, schema Schema, from *recordPosition,
+	fn func(p recordPosition, r *Record) error) error {
+	br := bufio.NewReader(r)
+	if isColumnar(br) {
+		return errColumnarRecords
+	}
+	skipped, next, err := skipWhitespaces(br)
+	if err != nil {
+		return err
+	}
+
+	var (
+		dr      io.Reader = br
+		base    int64
+		index   int
+		isArray = next == '['
+	)
+	if from != nil {
+		base, index, isArray = from.Offset, from.Index, from.Array
+		if isArray {
+			// The comma after the record is replaced by "[", so the decoder goes on as at the start of an array.
+			if next == ',' {
+				if _, err := br.ReadByte(); err != nil {
+					return err
+				}
+				skipped++
+			}
+			dr = io.MultiReader(strings.NewReader("["), br)
+			skipped--
+		}
+	}
+	base += skipped
+
+	d := json.NewDecoder(dr)
+	rd := newRecordDecoder(schema)
+	if isArray {
+		if err := nextToken(d); err != nil {
+			return err
+		}
+	}
+
+	for i := index + 1; d.More(); i++ {
 		r := &Record{}
-		if err := d.Decode(r); err != nil {
-			log.Fatalf("Error to decode %v", err.Error())
+		if err := rd.decode(d, r); err != nil {
+			return fmt.Errorf("error to decode record %d: %v", i, err.Error())
 		}
 
-		i++
-		if !r.IsValid() {
-			ignored++
-			logCount(isVerbose, i, parsed, ignored)
-			continue
+		r.parseDelivery()
+		if err := fn(recordPosition{base + d.InputOffset(), i, isArray}, r); err != nil {
+			return err
 		}
+	}
 
-		calc.Calculate(*r)
-		parsed++
-		logCount(isVerbose, i, parsed, ignored)
+	if isArray {
+		return nextToken(d)
 	}
-	nextToken(d)
 
-	return
+	return nil
 }
 
-func nextToken(d *json.Decoder) {
+// startsWithArray skips the leading whitespaces of r and checks if the input is a JSON array. An empty input is not.
+func startsWithArray(r *bufio.Reader) (bool, error) {
+	_, next, err := skipWhitespaces(r)
+
+	return next == '[', err
+}
+
+// skipWhitespaces skips the leading whitespaces of r. It returns how many were skipped and the byte that follows them,
+// which is left in r. The byte is 0 at the end of the input.
+func skipWhitespaces(r *bufio.Reader) (int64, byte, error) {
+	var skipped int64
+	for {
+		b, err := r.Peek(1)
+		if err == io.EOF {
+			return skipped, 0, nil
+		}
+		if err != nil {
+			return skipped, 0, err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\n', '\r':
+			if _, err := r.ReadByte(); err != nil {
+				return skipped, 0, err
+			}
+			skipped++
+		default:
+			return skipped, b[0], nil
+		}
+	}
+}
+
+func nextToken(d *json.Decoder) error {
 	if _, err := d.Token(); err != nil {
-		panic(fmt.Errorf("function Token() encountered an unexpected delimiter in the input %v", err.Error()))
+		return fmt.Errorf("function Token() encountered an unexpected delimiter in the input %v", err.Error())
 	}
+
+	return nil
 }
 
 func logCount(isVerbose bool, rc, pc, ic int) {
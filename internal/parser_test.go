@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"context"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -14,9 +16,9 @@ func TestParseIntegration(t *testing.T) {
 	defer removeFile()
 	mc := mockCalculator{results: []Record{}}
 	want := []Record{
-		{"10224", "Creamy Dill Chicken", "Wednesday 1AM - 7PM"},
-		{"10208", "Speedy Steak Fajitas", "Thursday 7AM - 5PM"},
-		{"10120", "Cherry Balsamic Pork Chops", "Thursday 7AM - 9PM"},
+		{"10224", "Creamy Dill Chicken", "Wednesday 1AM - 7PM", "", nil, DeliveryWindow{"Wednesday", 1, 19}},
+		{"10208", "Speedy Steak Fajitas", "Thursday 7AM - 5PM", "", nil, DeliveryWindow{"Thursday", 7, 17}},
+		{"10120", "Cherry Balsamic Pork Chops", "Thursday 7AM - 9PM", "", nil, DeliveryWindow{"Thursday", 7, 21}},
 	}
 	parsedWant := 3
 	ignoredWant := 5
@@ -29,6 +31,34 @@ func TestParseIntegration(t *testing.T) {
 	}
 }
 
+func TestParseContext(t *testing.T) {
+	createFile(fixture)
+	defer removeFile()
+	ctx, cancel := context.WithCancel(context.Background())
+	mc := mockCalculator{results: []Record{}}
+
+	parsed, ignored, err := ParseContext(ctx, stubFile, DefaultSchema, &cancellingCalculator{&mc, 2, cancel}, false)
+
+	if err != context.Canceled || parsed != 2 || ignored != 0 || len(mc.results) != 2 {
+		t.Errorf("Cancel, want: %v %v %v %v, got: %v %v %v %v", context.Canceled, 2, 0, 2, err, parsed, ignored,
+			len(mc.results))
+	}
+}
+
+// cancellingCalculator calls cancel after calculating n records.
+type cancellingCalculator struct {
+	Calculator
+	n      int
+	cancel context.CancelFunc
+}
+
+func (c *cancellingCalculator) Calculate(r Record) {
+	c.Calculator.Calculate(r)
+	if c.n--; c.n == 0 {
+		c.cancel()
+	}
+}
+
 type mockCalculator struct {
 	results []Record
 }
@@ -99,3 +129,33 @@ const (
   "delivery": ""
 }]`
 )
+
+func TestParseReader(t *testing.T) {
+	cases := []struct {
+		name        string
+		in          string
+		wantParsed  int
+		wantIgnored int
+		wantErr     bool
+	}{
+		{"JSON array", fixture, 3, 5, false},
+		{"NDJSON", `{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"}
+{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "1AM - 7PM"}`, 1, 1, false},
+		{"Empty array", " \n[]", 0, 0, false},
+		{"Empty input", "", 0, 0, false},
+		{"Invalid record", `[{"postcode": 10224}]`, 0, 0, true},
+		{"Unterminated array", `[{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"}`,
+			1, 0, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			parsed, ignored, err := ParseReader(strings.NewReader(c.in), &mockCalculator{}, false)
+
+			if parsed != c.wantParsed || ignored != c.wantIgnored || (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v %v %v, got: %v %v %v", c.name, c.wantParsed, c.wantIgnored, c.wantErr,
+					parsed, ignored, err)
+			}
+		})
+	}
+}
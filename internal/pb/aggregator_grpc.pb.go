@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: aggregator.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// AggregatorClient is the client API for Aggregator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AggregatorClient interface {
+	Aggregate(ctx context.Context, opts ...grpc.CallOption) (Aggregator_AggregateClient, error)
+	WatchAggregate(ctx context.Context, opts ...grpc.CallOption) (Aggregator_WatchAggregateClient, error)
+}
+
+type aggregatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAggregatorClient(cc grpc.ClientConnInterface) AggregatorClient {
+	return &aggregatorClient{cc}
+}
+
+func (c *aggregatorClient) Aggregate(ctx context.Context, opts ...grpc.CallOption) (Aggregator_AggregateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Aggregator_ServiceDesc.Streams[0], "/recipecount.Aggregator/Aggregate", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aggregatorAggregateClient{stream}
+	return x, nil
+}
+
+type Aggregator_AggregateClient interface {
+	Send(*AggregateRequest) error
+	CloseAndRecv() (*AggregateReply, error)
+	grpc.ClientStream
+}
+
+type aggregatorAggregateClient struct {
+	grpc.ClientStream
+}
+
+func (x *aggregatorAggregateClient) Send(m *AggregateRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aggregatorAggregateClient) CloseAndRecv() (*AggregateReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(AggregateReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aggregatorClient) WatchAggregate(ctx context.Context, opts ...grpc.CallOption) (Aggregator_WatchAggregateClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Aggregator_ServiceDesc.Streams[1], "/recipecount.Aggregator/WatchAggregate", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aggregatorWatchAggregateClient{stream}
+	return x, nil
+}
+
+type Aggregator_WatchAggregateClient interface {
+	Send(*AggregateRequest) error
+	Recv() (*AggregateReply, error)
+	grpc.ClientStream
+}
+
+type aggregatorWatchAggregateClient struct {
+	grpc.ClientStream
+}
+
+func (x *aggregatorWatchAggregateClient) Send(m *AggregateRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aggregatorWatchAggregateClient) Recv() (*AggregateReply, error) {
+	m := new(AggregateReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AggregatorServer is the server API for Aggregator service.
+// All implementations must embed UnimplementedAggregatorServer
+// for forward compatibility
+type AggregatorServer interface {
+	Aggregate(Aggregator_AggregateServer) error
+	WatchAggregate(Aggregator_WatchAggregateServer) error
+	mustEmbedUnimplementedAggregatorServer()
+}
+
+// UnimplementedAggregatorServer must be embedded to have forward compatible implementations.
+type UnimplementedAggregatorServer struct {
+}
+
+func (UnimplementedAggregatorServer) Aggregate(Aggregator_AggregateServer) error {
+	return status.Errorf(codes.Unimplemented, "method Aggregate not implemented")
+}
+func (UnimplementedAggregatorServer) WatchAggregate(Aggregator_WatchAggregateServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAggregate not implemented")
+}
+func (UnimplementedAggregatorServer) mustEmbedUnimplementedAggregatorServer() {}
+
+// UnsafeAggregatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AggregatorServer will
+// result in compilation errors.
+type UnsafeAggregatorServer interface {
+	mustEmbedUnimplementedAggregatorServer()
+}
+
+func RegisterAggregatorServer(s grpc.ServiceRegistrar, srv AggregatorServer) {
+	s.RegisterService(&Aggregator_ServiceDesc, srv)
+}
+
+func _Aggregator_Aggregate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AggregatorServer).Aggregate(&aggregatorAggregateServer{stream})
+}
+
+type Aggregator_AggregateServer interface {
+	SendAndClose(*AggregateReply) error
+	Recv() (*AggregateRequest, error)
+	grpc.ServerStream
+}
+
+type aggregatorAggregateServer struct {
+	grpc.ServerStream
+}
+
+func (x *aggregatorAggregateServer) SendAndClose(m *AggregateReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *aggregatorAggregateServer) Recv() (*AggregateRequest, error) {
+	m := new(AggregateRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Aggregator_WatchAggregate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AggregatorServer).WatchAggregate(&aggregatorWatchAggregateServer{stream})
+}
+
+type Aggregator_WatchAggregateServer interface {
+	Send(*AggregateReply) error
+	Recv() (*AggregateRequest, error)
+	grpc.ServerStream
+}
+
+type aggregatorWatchAggregateServer struct {
+	grpc.ServerStream
+}
+
+func (x *aggregatorWatchAggregateServer) Send(m *AggregateReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *aggregatorWatchAggregateServer) Recv() (*AggregateRequest, error) {
+	m := new(AggregateRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Aggregator_ServiceDesc is the grpc.ServiceDesc for Aggregator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Aggregator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "recipecount.Aggregator",
+	HandlerType: (*AggregatorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Aggregate",
+			Handler:       _Aggregator_Aggregate_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchAggregate",
+			Handler:       _Aggregator_WatchAggregate_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "aggregator.proto",
+}
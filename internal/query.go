@@ -0,0 +1,479 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// queryAliases are the other names of the fields in a query.
+	queryAliases = map[string]string{"from": "from_hour", "to": "to_hour"}
+	// queryStar are the columns of SELECT *.
+	queryStar = []string{"postcode", "recipe", "weekday", "from", "to"}
+)
+
+type (
+	// Query is a SQL-like query over the records parsed by ParseQuery and evaluated by RunQuery. The columns after
+	// the selected ones are only sorted by.
+	Query struct {
+		columns  []queryColumn
+		selected int
+		where    Expr
+		groupBy  []string
+		orderBy  []queryOrder
+		limit    int
+	}
+	// queryColumn is a column of the SELECT: count(*) if count is set, otherwise a field. name is its header: the
+	// field as it is written, count(*) or the name given by AS. offset is its position in the query, for errors.
+	queryColumn struct {
+		name   string
+		field  string
+		count  bool
+		offset int
+	}
+	// queryOrder sorts the rows by the column at index column, in descending order if desc is set.
+	queryOrder struct {
+		column int
+		desc   bool
+	}
+	queryParser struct {
+		whereParser
+	}
+)
+
+// ParseQuery parses a SQL-like query over the records. E.g.
+// "SELECT recipe, count(*) FROM deliveries WHERE postcode = '10120' GROUP BY recipe ORDER BY 2 DESC LIMIT 5".
+// The clauses are, in this order:
+//   - SELECT, followed by fields, count(*) or *, which is postcode, recipe, weekday, from and to. A column might be
+//     renamed by AS and a name;
+//   - FROM deliveries, the only table;
+//   - WHERE, optional, followed by a condition of ParseWhere;
+//   - GROUP BY, optional, followed by fields. The columns of a query with GROUP BY or count(*) must be counts or
+//     fields grouped by;
+//   - ORDER BY, optional, followed by columns, by name or by position starting at 1, or by fields and count(*) not
+//     selected, each one optionally followed by ASC or DESC. Numbers are compared as numbers;
+//   - LIMIT, optional, followed by the maximum number of rows.
+//
+// The fields are the ones of Record.Field, with from and to for from_hour and to_hour. Keywords are case insensitive.
+// It returns a *FilterError pointing at the first unexpected token.
+func ParseQuery(query string) (Query, error) {
+	tokens, err := lexWhere("query", query)
+	if err != nil {
+		return Query{}, err
+	}
+	tokens[len(tokens)-1].text = "end of the query"
+
+	p := &queryParser{whereParser{name: "query", where: query, tokens: tokens, aliases: queryAliases}}
+
+	return p.parse()
+}
+
+// Columns returns the headers of the columns of q.
+func (q Query) Columns() []string {
+	names := make([]string, q.selected)
+	for i, c := range q.columns[:q.selected] {
+		names[i] = c.name
+	}
+
+	return names
+}
+
+// ValidateFields checks that every field of q is a field of schema, so no column, condition or group is evaluated
+// with a field that is always empty.
+func (q Query) ValidateFields(schema Schema) error {
+	var err error
+	check := func(field string) {
+		if err == nil && !schema.HasField(field) {
+			err = fmt.Errorf("invalid query field %q, it is neither a record field nor an attribute", field)
+		}
+	}
+	for _, c := range q.columns {
+		if !c.count {
+			check(c.field)
+		}
+	}
+	whereFields(q.where, check)
+	for _, field := range q.groupBy {
+		check(field)
+	}
+
+	return err
+}
+
+// isGrouped tells if the rows of q are groups of records rather than records.
+func (q Query) isGrouped() bool {
+	if len(q.groupBy) > 0 {
+		return true
+	}
+	for _, c := range q.columns {
+		if c.count {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *queryParser) parse() (Query, error) {
+	q := Query{limit: -1}
+	var err error
+	if err := p.expect("SELECT"); err != nil {
+		return Query{}, err
+	}
+	if q.columns, err = p.parseColumns(); err != nil {
+		return Query{}, err
+	}
+	q.selected = len(q.columns)
+	if err := p.expect("FROM"); err != nil {
+		return Query{}, err
+	}
+	if t := p.next(); !p.isKeyword(t, "deliveries") {
+		return Query{}, p.fail(t, fmt.Sprintf("unexpected %v, expected the table deliveries", t))
+	}
+
+	if p.isKeyword(p.peek(), "WHERE") {
+		p.next()
+		if q.where, err = p.parseOr(); err != nil {
+			return Query{}, err
+		}
+	}
+	if p.isKeyword(p.peek(), "GROUP") {
+		p.next()
+		if q.groupBy, err = p.parseGroupBy(); err != nil {
+			return Query{}, err
+		}
+	}
+	if p.isKeyword(p.peek(), "ORDER") {
+		p.next()
+		if err := p.parseOrderBy(&q); err != nil {
+			return Query{}, err
+		}
+	}
+	if p.isKeyword(p.peek(), "LIMIT") {
+		p.next()
+		t := p.next()
+		n, err := strconv.Atoi(t.text)
+		if t.kind != tokenWord || err != nil || n < 0 {
+			return Query{}, p.fail(t, fmt.Sprintf("unexpected %v, expected the maximum number of rows, e.g. 5", t))
+		}
+		q.limit = n
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return Query{}, p.fail(t, fmt.Sprintf("unexpected %v, expected AND, OR or the next clause, e.g. ORDER BY", t))
+	}
+
+	if q.isGrouped() {
+		for _, c := range q.columns {
+			if !c.count && !contains(q.groupBy, c.field) {
+				return Query{}, &FilterError{p.name, p.where, c.offset,
+					fmt.Sprintf("%s must be in GROUP BY or counted, as the rows are grouped", c.name)}
+			}
+		}
+	}
+
+	return q, nil
+}
+
+// expect reads the keyword.
+func (p *queryParser) expect(keyword string) error {
+	if t := p.next(); !p.isKeyword(t, keyword) {
+		return p.fail(t, fmt.Sprintf("unexpected %v, expected %s", t, keyword))
+	}
+
+	return nil
+}
+
+func (p *queryParser) parseColumns() ([]queryColumn, error) {
+	var columns []queryColumn
+	for {
+		t := p.next()
+		if p.isOperator(t, "*") {
+			for _, name := range queryStar {
+				columns = append(columns, queryColumn{name: name, field: p.field(name), offset: t.offset})
+			}
+		} else {
+			c, err := p.parseColumn(t)
+			if err != nil {
+				return nil, err
+			}
+			if p.isKeyword(p.peek(), "AS") {
+				p.next()
+				name := p.next()
+				if (name.kind != tokenWord && name.kind != tokenString) || isQueryKeyword(name.text) {
+					return nil, p.fail(name, fmt.Sprintf("unexpected %v, expected a name after AS", name))
+				}
+				c.name = name.text
+			}
+			columns = append(columns, c)
+		}
+
+		if p.peek().kind != tokenComma {
+			return columns, nil
+		}
+		p.next()
+	}
+}
+
+// parseColumn reads count(*) or a field starting at t.
+func (p *queryParser) parseColumn(t whereToken) (queryColumn, error) {
+	if p.isKeyword(t, "COUNT") && p.peek().kind == tokenOpen {
+		p.next()
+		if star := p.next(); !p.isOperator(star, "*") {
+			return queryColumn{}, p.fail(star, fmt.Sprintf("unexpected %v, expected count(*)", star))
+		}
+		if c := p.next(); c.kind != tokenClose {
+			return queryColumn{}, p.fail(c, fmt.Sprintf("unexpected %v, expected count(*)", c))
+		}
+		return queryColumn{name: "count(*)", count: true, offset: t.offset}, nil
+	}
+
+	if t.kind != tokenWord || isQueryKeyword(t.text) {
+		return queryColumn{}, p.fail(t, fmt.Sprintf("unexpected %v, expected a field, e.g. recipe, or count(*)", t))
+	}
+
+	return queryColumn{name: t.text, field: p.field(t.text), offset: t.offset}, nil
+}
+
+func (p *queryParser) parseGroupBy() ([]string, error) {
+	if err := p.expect("BY"); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for {
+		t := p.next()
+		if t.kind != tokenWord || isQueryKeyword(t.text) {
+			return nil, p.fail(t, fmt.Sprintf("unexpected %v, expected a field, e.g. recipe", t))
+		}
+		fields = append(fields, p.field(t.text))
+
+		if p.peek().kind != tokenComma {
+			return fields, nil
+		}
+		p.next()
+	}
+}
+
+// parseOrderBy reads the ORDER BY of q. A field or count(*) that is not selected is added to the columns of q.
+func (p *queryParser) parseOrderBy(q *Query) error {
+	if err := p.expect("BY"); err != nil {
+		return err
+	}
+
+	for {
+		i, err := p.parseOrderColumn(q)
+		if err != nil {
+			return err
+		}
+		o := queryOrder{column: i}
+		if t := p.peek(); p.isKeyword(t, "DESC") || p.isKeyword(t, "ASC") {
+			p.next()
+			o.desc = p.isKeyword(t, "DESC")
+		}
+		q.orderBy = append(q.orderBy, o)
+
+		if p.peek().kind != tokenComma {
+			return nil
+		}
+		p.next()
+	}
+}
+
+// parseOrderColumn reads a column of q, by its position, its name or its field, and returns its index.
+func (p *queryParser) parseOrderColumn(q *Query) (int, error) {
+	t := p.next()
+	if n, err := strconv.Atoi(t.text); err == nil && t.kind == tokenWord {
+		if n < 1 || n > q.selected {
+			return 0, p.fail(t, fmt.Sprintf("column %d out of range, the query has %d columns", n, q.selected))
+		}
+		return n - 1, nil
+	}
+	for i, c := range q.columns[:q.selected] {
+		if t.kind == tokenWord && t.text == c.name {
+			return i, nil
+		}
+	}
+
+	ref, err := p.parseColumn(t)
+	if err != nil {
+		return 0, err
+	}
+	for i, c := range q.columns {
+		if c.count == ref.count && c.field == ref.field {
+			return i, nil
+		}
+	}
+	q.columns = append(q.columns, ref)
+
+	return len(q.columns) - 1, nil
+}
+
+func isQueryKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "SELECT", "WHERE", "GROUP", "BY", "ORDER", "LIMIT", "AS", "ASC", "DESC":
+		return true
+	default:
+		return isWhereKeyword(word)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RunQuery evaluates q over the valid records of files, decoded with schema, and calls emit with each row of its
+// result. The rows of a query that is neither grouped nor ordered are emitted as the records are read and the
+// parsing stops at its LIMIT. The others are emitted at the end: only the groups, or the rows of an ordered query,
+// are kept, and no more than twice the LIMIT of them if it is ordered.
+// It returns an error if a file cannot be read or parsed and ctx.Err() if ctx is done before the end of the files.
+func RunQuery(ctx context.Context, q Query, files []string, schema Schema, emit func(row []string)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	calc := &queryCalculator{q: q, emit: emit, cancel: cancel, groups: make(map[string]*queryGroup)}
+	for _, file := range files {
+		if _, _, err := ParseContext(ctx, file, schema, calc, false); err != nil {
+			if calc.done {
+				break
+			}
+			return err
+		}
+	}
+	calc.flush()
+
+	return nil
+}
+
+type (
+	// queryCalculator is a Calculator that evaluates a Query. cancel is called once its LIMIT is reached, so the
+	// parsing stops, and done is set.
+	queryCalculator struct {
+		q       Query
+		emit    func(row []string)
+		cancel  func()
+		done    bool
+		emitted int
+		groups  map[string]*queryGroup
+		keys    []string
+		rows    [][]string
+	}
+	// queryGroup is a group of records: the row of its first record and its count.
+	queryGroup struct {
+		row   []string
+		count int
+	}
+)
+
+// Calculate adds r to its group, to the rows to sort or emits its row if r matches the WHERE of the query.
+func (c *queryCalculator) Calculate(r Record) {
+	if c.done || (c.q.where != nil && !c.q.where.Match(r)) {
+		return
+	}
+
+	switch {
+	case c.q.isGrouped():
+		values := make([]string, len(c.q.groupBy))
+		for i, field := range c.q.groupBy {
+			values[i] = r.Field(field)
+		}
+		key := strings.Join(values, groupSeparator)
+		g, ok := c.groups[key]
+		if !ok {
+			g = &queryGroup{row: c.row(r)}
+			c.groups[key] = g
+			c.keys = append(c.keys, key)
+		}
+		g.count++
+	case len(c.q.orderBy) > 0:
+		c.rows = append(c.rows, c.row(r))
+		if c.q.limit >= 0 && len(c.rows) > 2*c.q.limit {
+			c.sortRows()
+			c.rows = c.rows[:c.q.limit]
+		}
+	default:
+		if c.q.limit < 0 || c.emitted < c.q.limit {
+			c.emit(c.row(r)[:c.q.selected])
+			c.emitted++
+		}
+		if c.q.limit >= 0 && c.emitted >= c.q.limit {
+			c.done = true
+			c.cancel()
+		}
+	}
+}
+
+// row returns the values of the columns of the query for r, without the counts.
+func (c *queryCalculator) row(r Record) []string {
+	row := make([]string, len(c.q.columns))
+	for i, col := range c.q.columns {
+		if !col.count {
+			row[i] = r.Field(col.field)
+		}
+	}
+
+	return row
+}
+
+// flush emits the groups or the ordered rows, sorted by the ORDER BY and up to the LIMIT. Groups are in the order
+// their first record was read, and a query counting without GROUP BY has a single row, even without records.
+func (c *queryCalculator) flush() {
+	if c.q.isGrouped() {
+		if len(c.q.groupBy) == 0 && len(c.keys) == 0 {
+			c.groups[""] = &queryGroup{row: make([]string, len(c.q.columns))}
+			c.keys = append(c.keys, "")
+		}
+		for _, key := range c.keys {
+			g := c.groups[key]
+			for i, col := range c.q.columns {
+				if col.count {
+					g.row[i] = strconv.Itoa(g.count)
+				}
+			}
+			c.rows = append(c.rows, g.row)
+		}
+	}
+
+	c.sortRows()
+	for i, row := range c.rows {
+		if c.q.limit >= 0 && i >= c.q.limit {
+			break
+		}
+		c.emit(row[:c.q.selected])
+	}
+}
+
+// sortRows sorts the rows by the ORDER BY of the query. Rows with equal values keep their order.
+func (c *queryCalculator) sortRows() {
+	sort.SliceStable(c.rows, func(i, j int) bool {
+		for _, o := range c.q.orderBy {
+			cmp := compareQueryValues(c.rows[i][o.column], c.rows[j][o.column])
+			if o.desc {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+}
+
+// compareQueryValues compares a and b as numbers if both are numbers, otherwise as strings.
+func compareQueryValues(a, b string) int {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		return compareNumbers(x, y)
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		name        string
+		query       string
+		wantColumns []string
+		wantGroupBy []string
+		wantOrderBy []queryOrder
+		wantLimit   int
+	}{
+		{"Example", "SELECT recipe, count(*) FROM deliveries WHERE postcode = '10120' GROUP BY recipe " +
+			"ORDER BY 2 DESC LIMIT 5", []string{"recipe", "count(*)"}, []string{"recipe"},
+			[]queryOrder{{1, true}}, 5},
+		{"Star and lower case", "select * from deliveries", []string{"postcode", "recipe", "weekday", "from", "to"},
+			nil, nil, -1},
+		{"Aliases", "SELECT from, to, count(*) AS n FROM deliveries GROUP BY from, to ORDER BY n DESC, from ASC",
+			[]string{"from", "to", "n"}, []string{"from_hour", "to_hour"}, []queryOrder{{2, true}, {0, false}}, -1},
+		{"Order by a column not selected", "SELECT recipe FROM deliveries ORDER BY weekday, recipe LIMIT 0",
+			[]string{"recipe"}, nil, []queryOrder{{1, false}, {0, false}}, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := ParseQuery(c.query)
+			if err != nil {
+				t.Fatalf("%s, want: %v, got: %v", c.name, nil, err)
+			}
+
+			if got := q.Columns(); !reflect.DeepEqual(c.wantColumns, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantColumns, got)
+			}
+			if !reflect.DeepEqual(c.wantGroupBy, q.groupBy) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantGroupBy, q.groupBy)
+			}
+			if !reflect.DeepEqual(c.wantOrderBy, q.orderBy) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantOrderBy, q.orderBy)
+			}
+			if c.wantLimit != q.limit {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantLimit, q.limit)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		query  string
+		offset int
+		reason string
+	}{
+		{"Missing SELECT", "recipe FROM deliveries", 0, `unexpected "recipe", expected SELECT`},
+		{"Missing FROM", "SELECT recipe", 13, "unexpected end of the query, expected FROM"},
+		{"Unknown table", "SELECT recipe FROM orders", 19, `unexpected "orders", expected the table deliveries`},
+		{"Invalid where", "SELECT recipe FROM deliveries WHERE recipe", 42,
+			"unexpected end of the query, expected an operator after recipe, e.g. = or IN"},
+		{"Count of a field", "SELECT count(recipe) FROM deliveries", 13, `unexpected "recipe", expected count(*)`},
+		{"Column not grouped", "SELECT recipe, count(*) FROM deliveries", 7,
+			"recipe must be in GROUP BY or counted, as the rows are grouped"},
+		{"Position out of range", "SELECT recipe FROM deliveries ORDER BY 2", 39,
+			"column 2 out of range, the query has 1 columns"},
+		{"Invalid limit", "SELECT recipe FROM deliveries LIMIT -1", 36,
+			`unexpected "-1", expected the maximum number of rows, e.g. 5`},
+		{"Clauses out of order", "SELECT recipe FROM deliveries LIMIT 1 ORDER BY recipe", 38,
+			`unexpected "ORDER", expected AND, OR or the next clause, e.g. ORDER BY`},
+		{"Keyword as name", "SELECT recipe AS limit FROM deliveries", 17,
+			`unexpected "limit", expected a name after AS`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			want := &FilterError{"query", c.query, c.offset, c.reason}
+			_, got := ParseQuery(c.query)
+
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, want, got)
+			}
+		})
+	}
+}
+
+func TestQueryValidateFields(t *testing.T) {
+	q, err := ParseQuery("SELECT box_size, count(*) FROM deliveries WHERE from >= 10 GROUP BY box_size")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.ValidateFields(DefaultSchema); err == nil {
+		t.Errorf("Unknown attribute, want: %v, got: %v", "error", err)
+	}
+	if err := q.ValidateFields(Schema{Attributes: []Attribute{{"box_size", "box_size"}}}); err != nil {
+		t.Errorf("Attribute, want: %v, got: %v", nil, err)
+	}
+}
+
+func TestRunQuery(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		files []string
+		want  [][]string
+	}{
+		{"Groups in the order they are found", "SELECT weekday, count(*) FROM deliveries GROUP BY weekday",
+			[]string{sampleFile}, [][]string{{"Wednesday", "8"}, {"Thursday", "3"}, {"Saturday", "4"},
+				{"Friday", "3"}, {"Monday", "2"}}},
+		{"Ordered groups", "SELECT weekday, count(*) AS n FROM deliveries GROUP BY weekday ORDER BY n DESC, weekday " +
+			"LIMIT 3", []string{sampleFile}, [][]string{{"Wednesday", "8"}, {"Saturday", "4"}, {"Friday", "3"}}},
+		{"Where", "SELECT recipe, count(*) FROM deliveries WHERE recipe contains Chicken GROUP BY recipe " +
+			"ORDER BY 2 DESC, 1 LIMIT 2", []string{sampleFile}, [][]string{{"Chicken Sausage Pizzas", "1"},
+			{"Creamy Dill Chicken", "1"}}},
+		{"Rows", "SELECT * FROM deliveries LIMIT 2", []string{sampleFile},
+			[][]string{{"10224", "Creamy Dill Chicken", "Wednesday", "1", "19"},
+				{"10208", "Speedy Steak Fajitas", "Thursday", "7", "17"}}},
+		{"Ordered rows", "SELECT recipe FROM deliveries ORDER BY from DESC, recipe LIMIT 3", []string{sampleFile},
+			[][]string{{"Garden Quesadillas"}, {"Garlic Herb Butter Steak"}, {"Spanish One-Pan Chicken"}}},
+		{"Count of no record", "SELECT count(*) FROM deliveries WHERE postcode = none", []string{sampleFile},
+			[][]string{{"0"}}},
+		{"Limit 0", "SELECT recipe FROM deliveries LIMIT 0", []string{sampleFile}, nil},
+		{"Files", "SELECT count(*) FROM deliveries", []string{sampleFile, sampleFile}, [][]string{{"40"}}},
+		{"Limit across files", "SELECT postcode FROM deliveries LIMIT 1", []string{sampleFile, "missing.json"},
+			[][]string{{"10224"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := ParseQuery(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got [][]string
+			err = RunQuery(context.Background(), q, c.files, DefaultSchema, func(row []string) {
+				got = append(got, row)
+			})
+
+			if err != nil || !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v %v", c.name, c.want, got, err)
+			}
+		})
+	}
+}
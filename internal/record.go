@@ -1,87 +1,277 @@
 package internal
 
 import (
-	"regexp"
+	"fmt"
 	"strconv"
 )
 
 // Record represents each Record of the delivered recipes list that are into the input JSON file.
+// Parse fills window with Delivery parsed once, so the hot path does not parse it for each check. Records created
+// otherwise parse Delivery on demand. DeliveryDate is optional: older inputs only have the other three fields.
+// Attributes are the extra values kept by the Schema of the input, by their names. It is nil without them.
 type Record struct {
-	Postcode string
-	Recipe   string
-	Delivery string
+	Postcode     string
+	Recipe       string
+	Delivery     string
+	DeliveryDate string            `json:"delivery_date"`
+	Attributes   map[string]string `json:"-"`
+	window       DeliveryWindow
+}
+
+// DeliveryWindow is a parsed delivery in the contract format: "{Weekday} {H}AM - {H}PM". The weekday is optional.
+// From and To are hours in the 24-hour clock. E.g. "Wednesday 1AM - 7PM": Weekday == "Wednesday", From == 1, To == 19.
+type DeliveryWindow struct {
+	Weekday string
+	From    int
+	To      int
+}
+
+// ParseDeliveryWindow parses a delivery in the contract format without allocating. It accepts the same inputs as the
+// regex `^([^\s]+\s+)?(1[0-2]|0?[1-9])[Aa][Mm]\s+\-\s+(1[0-2]|0?[1-9])[Pp][Mm]`. Any content after the end hour is
+// ignored. It returns false if delivery does not match the format.
+func ParseDeliveryWindow(delivery string) (DeliveryWindow, bool) {
+	if len(delivery) > 0 && isDigit(delivery[0]) {
+		if w, ok := parseHours(delivery, 0); ok {
+			return w, true
+		}
+	}
+
+	i := 0
+	for i < len(delivery) && !isSpace(delivery[i]) {
+		i++
+	}
+	weekday := delivery[:i]
+	if weekday == "" {
+		return DeliveryWindow{}, false
+	}
+
+	i, ok := skipSpaces(delivery, i)
+	if !ok {
+		return DeliveryWindow{}, false
+	}
+
+	w, ok := parseHours(delivery, i)
+	if !ok {
+		return DeliveryWindow{}, false
+	}
+	w.Weekday = weekday
+
+	return w, true
+}
+
+// ParseDeliveryDate parses an ISO 8601 date, "YYYY-MM-DD", optionally followed by a time. E.g. "2026-10-01" or
+// "2026-10-01T10:00:00Z". It returns the date without the time and false if date does not start with a valid date.
+func ParseDeliveryDate(date string) (string, bool) {
+	if len(date) < 10 || (len(date) > 10 && date[10] != 'T' && date[10] != ' ') {
+		return "", false
+	}
+	if i, _ := checkDate(date); i >= 0 {
+		return "", false
+	}
+
+	return date[:10], true
 }
 
 // DeliveredBetween receives a range in the contract format: "{H}AM - {H}PM" and checks if the delivery time range
 // of the current Record matches with the input criteria.
 // If any format error occurs it returns false.
 func (r Record) DeliveredBetween(timeRange string) bool {
-	begin, err := r.getBeginHour(timeRange)
+	tr, err := parseTimeRange(timeRange)
 	if err != nil {
 		return false
 	}
 
-	end, err := r.getEndHour(timeRange)
-	if err != nil {
-		return false
-	}
-
-	return r.beginHour() <= begin && r.endHour() <= end
+	return r.deliveredBetween(tr)
 }
 
 // IsValid checks all properties of the current record according functional requirements. If any checked fails
 // it returns false.
 func (r Record) IsValid() bool {
-	recipeRegex := `^[^\s]+\s+(1[0-2]|0?[1-9])[Aa][Mm]\s+\-\s+(1[0-2]|0?[1-9])[Pp][Mm]`
+	return r.Validate() == nil
+}
+
+// Validate checks the same properties as IsValid.
+// It returns an error describing the first property that fails.
+func (r Record) Validate() error {
 	maxCharacterRecipeAllowed := 100
 	maxCharacterPostcodeAllowed := 10
-	validDelivery, err := regexp.MatchString(recipeRegex, r.Delivery)
-	validRecipe := len(r.Recipe) <= maxCharacterRecipeAllowed && len(r.Recipe) > 0
-	validPostcode := len(r.Postcode) <= maxCharacterPostcodeAllowed && len(r.Postcode) > 0
+	if len(r.Recipe) > maxCharacterRecipeAllowed || len(r.Recipe) == 0 {
+		return fmt.Errorf("recipe must have 1 to %d characters, got %d", maxCharacterRecipeAllowed, len(r.Recipe))
+	}
+	if len(r.Postcode) > maxCharacterPostcodeAllowed || len(r.Postcode) == 0 {
+		return fmt.Errorf("postcode must have 1 to %d characters, got %d", maxCharacterPostcodeAllowed,
+			len(r.Postcode))
+	}
+	if w, ok := r.deliveryWindow(); !ok || w.Weekday == "" {
+		return fmt.Errorf("delivery %q does not match the format \"{Weekday} {H}AM - {H}PM\"", r.Delivery)
+	}
+	if _, ok := r.deliveryDate(); !ok && r.DeliveryDate != "" {
+		return fmt.Errorf("delivery_date %q is not a date in the format \"YYYY-MM-DD\"", r.DeliveryDate)
+	}
+
+	return nil
+}
+
+// parseDelivery parses Delivery into window. It is called once by Parse for each decoded record.
+func (r *Record) parseDelivery() {
+	r.window, _ = ParseDeliveryWindow(r.Delivery)
+}
+
+// deliveryWindow returns window if Delivery was already parsed. A parsed window always has From greater than zero.
+func (r Record) deliveryWindow() (DeliveryWindow, bool) {
+	if r.window.From > 0 {
+		return r.window, true
+	}
+
+	return ParseDeliveryWindow(r.Delivery)
+}
 
-	return  validRecipe &&
-		validPostcode &&
-		err == nil &&
-		validDelivery
+// Field returns the value of the field name of r: postcode, recipe, delivery, delivery_date, without its time, the
+// weekday, from_hour and to_hour of the delivery, in 24-hour format, or one of its Attributes. It is empty for an
+// unknown name or an invalid delivery.
+func (r Record) Field(name string) string {
+	switch name {
+	case "postcode":
+		return r.Postcode
+	case "recipe":
+		return r.Recipe
+	case "delivery":
+		return r.Delivery
+	case "delivery_date":
+		date, _ := r.deliveryDate()
+		return date
+	case "weekday", "from_hour", "to_hour":
+		w, ok := r.deliveryWindow()
+		if !ok {
+			return ""
+		}
+		switch name {
+		case "weekday":
+			return w.Weekday
+		case "from_hour":
+			return strconv.Itoa(w.From)
+		default:
+			return strconv.Itoa(w.To)
+		}
+	default:
+		return r.Attributes[name]
+	}
 }
 
-func (r Record) beginHour() int {
-	begin, _ := r.getBeginHour(r.Delivery)
+// isRecordField tells if name is a field of Record.Field other than an attribute.
+func isRecordField(name string) bool {
+	switch name {
+	case "postcode", "recipe", "delivery", "delivery_date", "weekday", "from_hour", "to_hour":
+		return true
+	default:
+		return false
+	}
+}
 
-	return begin
+// deliveryDate returns the date of DeliveryDate. It returns false if it is empty or invalid.
+func (r Record) deliveryDate() (string, bool) {
+	if r.DeliveryDate == "" {
+		return "", false
+	}
+
+	return ParseDeliveryDate(r.DeliveryDate)
 }
 
-func (r Record) endHour() int {
-	end, _ := r.getEndHour(r.Delivery)
+func (r Record) deliveredBetween(tr DeliveryWindow) bool {
+	w, ok := r.deliveryWindow()
 
-	return end
+	return ok && w.From <= tr.From && w.To <= tr.To
 }
 
-func (r Record) getBeginHour(timeRange string) (int, error) {
-	bhStr, err := GetBeginHourStr(timeRange)
+// parseTimeRange parses a filter time range. Unlike ParseDeliveryWindow it looks for the begin and end hours anywhere
+// in timeRange, so "10AM-3PM" and "Friday 10AM - 2PM" are accepted as well.
+func parseTimeRange(timeRange string) (DeliveryWindow, error) {
+	begin, err := getHour(timeRange, GetBeginHourStr, 0)
 	if err != nil {
-		return 0, err
+		return DeliveryWindow{}, err
 	}
 
-	hour, err := strconv.Atoi(bhStr[1])
+	end, err := getHour(timeRange, GetEndHourStr, 12)
 	if err != nil {
-		return 0, err
+		return DeliveryWindow{}, err
 	}
 
-	return hour, nil
+	return DeliveryWindow{From: begin, To: end}, nil
 }
 
-func (r Record) getEndHour(timeRange string) (int, error) {
-	diffTo24Hour := 12
-
-	endHour, err := GetEndHourStr(timeRange)
+func getHour(timeRange string, hourStr func(string) ([]string, error), diffTo24Hour int) (int, error) {
+	matches, err := hourStr(timeRange)
 	if err != nil {
 		return 0, err
 	}
-	hour, err := strconv.Atoi(endHour[1])
-	if err != nil {
-		return 0, err
+
+	return atoi(matches[1]) + diffTo24Hour, nil
+}
+
+// parseHours parses "{H}AM - {H}PM" at s[i:].
+func parseHours(s string, i int) (DeliveryWindow, bool) {
+	var (
+		w  DeliveryWindow
+		ok bool
+	)
+
+	if w.From, i, ok = parseHour(s, i, 'a'); !ok {
+		return DeliveryWindow{}, false
+	}
+	if i, ok = skipSpaces(s, i); !ok || i >= len(s) || s[i] != '-' {
+		return DeliveryWindow{}, false
+	}
+	if i, ok = skipSpaces(s, i+1); !ok {
+		return DeliveryWindow{}, false
+	}
+	if w.To, _, ok = parseHour(s, i, 'p'); !ok {
+		return DeliveryWindow{}, false
 	}
+	w.To += 12
+
+	return w, true
+}
+
+// parseHour parses "(1[0-2]|0?[1-9])" followed by the meridiem starting with m (lower case) at s[i:]. It returns the
+// hour and the index after the meridiem.
+func parseHour(s string, i int, m byte) (int, int, bool) {
+	start := i
+	for i < len(s) && i-start < 2 && isDigit(s[i]) {
+		i++
+	}
+
+	if hour := atoi(s[start:i]); hour >= 1 && hour <= 12 && i+1 < len(s) && s[i]|0x20 == m && s[i+1]|0x20 == 'm' {
+		return hour, i + 2, true
+	}
+
+	return 0, i, false
+}
+
+// skipSpaces skips the whitespaces at s[i:] and returns the next index. It returns false if there is none.
+func skipSpaces(s string, i int) (int, bool) {
+	start := i
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+
+	return i, i > start
+}
+
+// atoi converts a string of digits without allocating. It must be called only with digits.
+func atoi(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		n = n*10 + int(s[i]-'0')
+	}
+
+	return n
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
 
-	return hour + diffTo24Hour, nil
+// isSpace reports whether c is a whitespace according the regex \s class.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
 }
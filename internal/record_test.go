@@ -1,6 +1,9 @@
 package internal
 
-import "testing"
+import (
+	"regexp"
+	"testing"
+)
 
 func TestDeliveredBetween(t *testing.T) {
 	cases := []struct {
@@ -27,3 +30,118 @@ func TestDeliveredBetween(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDeliveryWindow(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     string
+		want   DeliveryWindow
+		wantOk bool
+	}{
+		{"Weekday", "Wednesday 1AM - 7PM", DeliveryWindow{"Wednesday", 1, 19}, true},
+		{"Without weekday", "10AM - 2PM", DeliveryWindow{"", 10, 14}, true},
+		{"Lower case and leading zero", "Friday 09am - 12pm", DeliveryWindow{"Friday", 9, 24}, true},
+		{"Many whitespaces", "Monday\t 8AM  -\t3PM", DeliveryWindow{"Monday", 8, 15}, true},
+		{"Trailing content", "Sunday 8AM - 3PM (late)", DeliveryWindow{"Sunday", 8, 15}, true},
+		{"Invalid - EMPTY", "", DeliveryWindow{}, false},
+		{"Invalid - GARBAGE", "ZAMBAS", DeliveryWindow{}, false},
+		{"Invalid - hour out of range", "Friday 13AM - 2PM", DeliveryWindow{}, false},
+		{"Invalid - zero hour", "Friday 00AM - 2PM", DeliveryWindow{}, false},
+		{"Invalid - swapped meridiem", "Friday 10PM - 2AM", DeliveryWindow{}, false},
+		{"Invalid - missing whitespaces", "Friday 10AM-2PM", DeliveryWindow{}, false},
+		{"Invalid - missing end hour", "Friday 10AM - ", DeliveryWindow{}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got, gotOk := ParseDeliveryWindow(c.in); got != c.want || gotOk != c.wantOk {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantOk, got, gotOk)
+			}
+		})
+	}
+}
+
+func TestParseDeliveryDate(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     string
+		want   string
+		wantOk bool
+	}{
+		{"Date", "2026-10-01", "2026-10-01", true},
+		{"Date and time", "2026-10-01T10:00:00Z", "2026-10-01", true},
+		{"Date, space and time", "2026-10-01 10:00", "2026-10-01", true},
+		{"Invalid - EMPTY", "", "", false},
+		{"Invalid - day out of range", "2026-09-31", "", false},
+		{"Invalid - not ISO 8601", "01/10/2026", "", false},
+		{"Invalid - trailing content", "2026-10-012", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got, gotOk := ParseDeliveryDate(c.in); got != c.want || gotOk != c.wantOk {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantOk, got, gotOk)
+			}
+		})
+	}
+}
+
+func TestIsValidMatchesContractRegex(t *testing.T) {
+	contractRegex := regexp.MustCompile(`^[^\s]+\s+(1[0-2]|0?[1-9])[Aa][Mm]\s+\-\s+(1[0-2]|0?[1-9])[Pp][Mm]`)
+	deliveries := []string{
+		"Wednesday 1AM - 7PM", "Thursday 7AM - 5PM", "1AM - 7PM", "", "Thursday 10am - 12pm", "Thursday 012AM - 1PM",
+		"Thursday 1AM -7PM", "Thursday  1AM  -  7PM", "Thursday 1 AM - 7 PM", "1st 10AM - 2PM", "Friday 123AM - 2PM",
+		"Friday 10AM - 2PMX", "Friday 10AM - 0PM", "Friday\n10AM\r-\f2PM", "Friday 10AM - 2P", "Friday 10AM",
+	}
+
+	for _, d := range deliveries {
+		r := Record{Postcode: "10120", Recipe: "Cherry Balsamic Pork Chops", Delivery: d}
+		if want, got := contractRegex.MatchString(d), r.IsValid(); want != got {
+			t.Errorf("IsValid(%q), want: %v, got: %v", d, want, got)
+		}
+	}
+}
+
+func TestParseDeliveryWindowAllocations(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		ParseDeliveryWindow("Wednesday 1AM - 7PM")
+	})
+
+	if allocs != 0 {
+		t.Errorf("ParseDeliveryWindow allocations, want: 0, got: %v", allocs)
+	}
+}
+
+func BenchmarkParseDeliveryWindow(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ParseDeliveryWindow("Wednesday 1AM - 7PM")
+	}
+}
+
+func BenchmarkIsValid(b *testing.B) {
+	r := Record{Postcode: "10224", Recipe: "Creamy Dill Chicken", Delivery: "Wednesday 1AM - 7PM"}
+	b.Run("unparsed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.IsValid()
+		}
+	})
+	r.parseDelivery()
+	b.Run("parsed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.IsValid()
+		}
+	})
+}
+
+func BenchmarkDeliveredBetween(b *testing.B) {
+	r := Record{Postcode: "10224", Recipe: "Creamy Dill Chicken", Delivery: "Wednesday 1AM - 7PM"}
+	r.parseDelivery()
+	tr, _ := parseTimeRange("10AM - 3PM")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.deliveredBetween(tr)
+	}
+}
@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultSchema is the schema of the contract: each Record field is a top-level key with its own name.
+var DefaultSchema = Schema{Postcode: "postcode", Recipe: "recipe", Delivery: "delivery",
+	DeliveryDate: "delivery_date"}
+
+type (
+	// Schema maps each Record field to the JSON path of its value in the input records. A path is a dot-separated
+	// list of object keys. E.g. "order.delivery" is the delivery key of the order object of each record. A key
+	// matches case-insensitively if the object has no exact match and, if several keys differ only by case, the
+	// lowest one in byte order wins. Attributes are the extra values kept in Record.Attributes. The zero Schema is
+	// DefaultSchema.
+	Schema struct {
+		Postcode     string
+		Recipe       string
+		Delivery     string
+		DeliveryDate string
+		Attributes   []Attribute
+	}
+	// Attribute is an extra value of the records kept by a Schema: its name and its JSON path.
+	Attribute struct {
+		Name string
+		Path string
+	}
+)
+
+// ParseSchema parses a comma separated list of field=path pairs over DefaultSchema. The fields are postcode, recipe,
+// delivery and delivery_date. E.g. "postcode=zip,recipe=recipe_name,delivery=order.delivery".
+// It returns an error if a pair is not field=path, if a field is unknown or repeated or if a path has an empty key.
+func ParseSchema(mapping string) (Schema, error) {
+	s := DefaultSchema
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(mapping, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return Schema{}, fmt.Errorf("invalid schema mapping %q, want the format field=path", pair)
+		}
+		field, path := strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+		if seen[field] {
+			return Schema{}, fmt.Errorf("invalid schema mapping %q, %s is mapped twice", pair, field)
+		}
+		seen[field] = true
+
+		p := s.field(field)
+		if p == nil {
+			return Schema{}, fmt.Errorf("invalid schema mapping %q, unknown field %q, want postcode, recipe, "+
+				"delivery or delivery_date", pair, field)
+		}
+		if err := validatePath(path); err != nil {
+			return Schema{}, fmt.Errorf("invalid schema mapping %q, %v", pair, err)
+		}
+		*p = path
+	}
+
+	return s, nil
+}
+
+// ParseAttributes parses a comma separated list of attributes. Each one is either a name, which is also its path, or a
+// name=path pair. E.g. "box_size,customer_type,country=market.country".
+// It returns an error if a name is repeated or is the name of a Record field, such as postcode, or if a path has an
+// empty key.
+func ParseAttributes(list string) ([]Attribute, error) {
+	var (
+		attrs []Attribute
+		seen  = make(map[string]bool)
+	)
+	for _, attr := range strings.Split(list, ",") {
+		attr = strings.TrimSpace(attr)
+		if attr == "" {
+			continue
+		}
+
+		name, path := attr, attr
+		if i := strings.Index(attr, "="); i >= 0 {
+			name, path = strings.TrimSpace(attr[:i]), strings.TrimSpace(attr[i+1:])
+		}
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("invalid attribute %q, want a name or the format name=path", attr)
+		case seen[name]:
+			return nil, fmt.Errorf("invalid attribute %q, %s is repeated", attr, name)
+		case isRecordField(name):
+			return nil, fmt.Errorf("invalid attribute %q, %s is a record field", attr, name)
+		}
+		if err := validatePath(path); err != nil {
+			return nil, fmt.Errorf("invalid attribute %q, %v", attr, err)
+		}
+		seen[name] = true
+		attrs = append(attrs, Attribute{name, path})
+	}
+
+	return attrs, nil
+}
+
+// HasField tells if name is a field of Record.Field: a Record field or one of the Attributes of s.
+func (s Schema) HasField(name string) bool {
+	if isRecordField(name) {
+		return true
+	}
+	for _, a := range s.Attributes {
+		if a.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validatePath(path string) error {
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			return fmt.Errorf("path %q has an empty key", path)
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) field(name string) *string {
+	switch name {
+	case "postcode":
+		return &s.Postcode
+	case "recipe":
+		return &s.Recipe
+	case "delivery":
+		return &s.Delivery
+	case "delivery_date":
+		return &s.DeliveryDate
+	default:
+		return nil
+	}
+}
+
+// fields returns the paths of the Record fields in the order of recordDecoder.paths.
+func (s Schema) fields() [4]string {
+	return [4]string{s.Postcode, s.Recipe, s.Delivery, s.DeliveryDate}
+}
+
+func (s Schema) String() string {
+	str := fmt.Sprintf("postcode=%s,recipe=%s,delivery=%s,delivery_date=%s", s.Postcode, s.Recipe, s.Delivery,
+		s.DeliveryDate)
+	for _, a := range s.Attributes {
+		str += fmt.Sprintf(",%s=%s", a.Name, a.Path)
+	}
+
+	return str
+}
+
+// recordDecoder decodes the records of a Schema. The paths are split once, so they are not split for each record.
+// Without paths, records are decoded straight into Record, as the default mapping of encoding/json does.
+type recordDecoder struct {
+	paths      [4][]string
+	attributes []Attribute
+	attrPaths  [][]string
+	keyed      bool
+}
+
+func newRecordDecoder(s Schema) recordDecoder {
+	fields := s.fields()
+	if fields == (Schema{}).fields() {
+		fields = DefaultSchema.fields()
+	}
+	if len(s.Attributes) == 0 && fields == DefaultSchema.fields() {
+		return recordDecoder{}
+	}
+
+	d := recordDecoder{attributes: s.Attributes, keyed: true}
+	for i, path := range fields {
+		d.paths[i] = strings.Split(path, ".")
+	}
+	for _, a := range s.Attributes {
+		d.attrPaths = append(d.attrPaths, strings.Split(a.Path, "."))
+	}
+
+	return d
+}
+
+// decode decodes the next record of d into r. Numbers are kept as they are written, so a numeric zip is a postcode,
+// and a missing path or a null leaves its field or attribute empty.
+// It returns an error if the record is not a JSON object or if a path has an object, an array or a boolean.
+func (rd recordDecoder) decode(d *json.Decoder, r *Record) error {
+	if !rd.keyed {
+		return d.Decode(r)
+	}
+
+	var v map[string]interface{}
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+
+	for i, field := range []*string{&r.Postcode, &r.Recipe, &r.Delivery, &r.DeliveryDate} {
+		value, err := lookup(v, rd.paths[i])
+		if err != nil {
+			return err
+		}
+		*field = value
+	}
+	if len(rd.attributes) == 0 {
+		return nil
+	}
+
+	r.Attributes = make(map[string]string, len(rd.attributes))
+	for i, a := range rd.attributes {
+		value, err := lookup(v, rd.attrPaths[i])
+		if err != nil {
+			return err
+		}
+		r.Attributes[a.Name] = value
+	}
+
+	return nil
+}
+
+// lookup returns the string or number at path of v.
+func lookup(v map[string]interface{}, path []string) (string, error) {
+	var value interface{} = v
+	for _, key := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", nil
+		}
+		value = objectValue(object, key)
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	default:
+		return "", fmt.Errorf("%s is %v, want a string or a number", strings.Join(path, "."), value)
+	}
+}
+
+// objectValue returns the value of key in object. Without an exact match, it is the value of the key equal to key
+// under case folding, the lowest one in byte order if several are. Unlike encoding/json, where the last matching key
+// of the object wins, the order of the keys is lost once decoded into a map, so the lowest one keeps the value
+// deterministic.
+func objectValue(object map[string]interface{}, key string) interface{} {
+	if value, ok := object[key]; ok {
+		return value
+	}
+
+	var (
+		match string
+		found bool
+	)
+	for k := range object {
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, found = k, true
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	return object[match]
+}
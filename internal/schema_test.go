@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSchema(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		want    Schema
+		wantErr bool
+	}{
+		{"Empty", "", DefaultSchema, false},
+		{"Mapping", "postcode=zip, recipe=recipe_name,delivery=order.delivery",
+			Schema{"zip", "recipe_name", "order.delivery", "delivery_date", nil}, false},
+		{"Delivery date", "delivery_date=order.date", Schema{"postcode", "recipe", "delivery", "order.date", nil},
+			false},
+		{"Missing path", "postcode", Schema{}, true},
+		{"Unknown field", "zip=postcode", Schema{}, true},
+		{"Repeated field", "postcode=zip,postcode=postal_code", Schema{}, true},
+		{"Empty path", "postcode=", Schema{}, true},
+		{"Empty key", "delivery=order..delivery", Schema{}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseSchema(c.in)
+
+			if (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantErr, err)
+			}
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeRecordsSchema(t *testing.T) {
+	schema, err := ParseSchema("postcode=zip,recipe=recipe_name,delivery=order.delivery,delivery_date=order.date")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		in      string
+		want    []Record
+		wantErr bool
+	}{
+		{"Array", `[{"zip": "10224", "recipe_name": "Creamy Dill Chicken", "order": {"delivery": "Wednesday 1AM - 7PM",
+			"date": "2026-10-01"}}]`, []Record{{"10224", "Creamy Dill Chicken", "Wednesday 1AM - 7PM", "2026-10-01",
+			nil, DeliveryWindow{"Wednesday", 1, 19}}}, false},
+		{"NDJSON and numeric zip", `{"zip": 10208, "recipe_name": "Speedy Steak Fajitas",
+			"order": {"delivery": "Thursday 7AM - 5PM"}}`, []Record{{"10208", "Speedy Steak Fajitas",
+			"Thursday 7AM - 5PM", "", nil, DeliveryWindow{"Thursday", 7, 17}}}, false},
+		{"Missing and null paths", `{"postcode": "10120", "recipe_name": null, "order": "Thursday 7AM - 9PM"}`,
+			[]Record{{}}, false},
+		{"Keys of another case", `{"ZIP": "10224", "Recipe_Name": "Creamy Dill Chicken", "recipe_NAME": "Other",
+			"Order": {"Delivery": "Wednesday 1AM - 7PM"}}`, []Record{{"10224", "Creamy Dill Chicken",
+			"Wednesday 1AM - 7PM", "", nil, DeliveryWindow{"Wednesday", 1, 19}}}, false},
+		{"Exact key first", `{"Zip": "10120", "zip": "10224"}`, []Record{{Postcode: "10224"}}, false},
+		{"Object value", `{"zip": {"code": "10120"}}`, nil, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []Record
+			err := decodeRecords(strings.NewReader(c.in), schema, func(i int, r *Record) {
+				got = append(got, *r)
+			})
+
+			if (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantErr, err)
+			}
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		want    []Attribute
+		wantErr bool
+	}{
+		{"Empty", "", nil, false},
+		{"Names and paths", "box_size, country=market.country",
+			[]Attribute{{"box_size", "box_size"}, {"country", "market.country"}}, false},
+		{"Missing name", "=market.country", nil, true},
+		{"Repeated name", "country,country=market.country", nil, true},
+		{"Record field", "postcode=zip", nil, true},
+		{"Empty key", "country=market.", nil, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseAttributes(c.in)
+
+			if (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.wantErr, err)
+			}
+			if !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeRecordsAttributes(t *testing.T) {
+	attrs, err := ParseAttributes("box_size,customer_type,country=market.country")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := `[{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM", "box_size": 4,
+		"market": {"country": "DE"}}]`
+	want := []Record{{"10224", "Creamy Dill Chicken", "Wednesday 1AM - 7PM", "",
+		map[string]string{"box_size": "4", "customer_type": "", "country": "DE"}, DeliveryWindow{"Wednesday", 1, 19}}}
+
+	var got []Record
+	schema := Schema{Attributes: attrs}
+	if err := decodeRecords(strings.NewReader(in), schema, func(i int, r *Record) { got = append(got, *r) }); err != nil {
+		t.Fatalf("Attributes, want: %v, got: %v", nil, err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Attributes, want: %v, got: %v", want, got)
+	}
+}
@@ -0,0 +1,473 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errUploadTooLarge = errors.New("upload too large")
+
+// tempFile is a temporary file removed when it is closed.
+type tempFile struct {
+	*os.File
+}
+
+// Default values of ServerConfig.
+const (
+	DefaultMaxBodyBytes    = 100 << 20
+	DefaultMaxUploadBytes  = 10 << 30
+	DefaultReadTimeout     = 5 * time.Minute
+	DefaultWriteTimeout    = 5 * time.Minute
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// ServerConfig is the information needed to serve the aggregation over HTTP. DefaultFilter holds the filter values
+// used when a request does not set them. Aliases and Regions are the maps used by RecipeNormalizer and
+// PostcodeNormalizer. MaxUploadBytes limits the files uploaded to jobs and DataDir is the directory of the files that
+// jobs might read by path; jobs cannot read files by path if it is empty. JobsDir is the directory of FileJobStore;
+// jobs are kept in memory if it is empty, up to MaxEndedJobs ended jobs. Dataset, which might be nil, is the Dataset
+// aggregated by GET /dataset; its records must be normalized by Aliases and Regions. Zero durations, sizes, counts and
+// DefaultFilter.Threshold fall back to the defaults.
+type ServerConfig struct {
+	Addr            string
+	MaxBodyBytes    int64
+	MaxUploadBytes  int64
+	DataDir         string
+	JobsDir         string
+	MaxEndedJobs    int
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	ShutdownTimeout time.Duration
+	DefaultFilter   Filter
+	Aliases         map[string]string
+	Regions         map[string]string
+	Dataset         *Dataset
+}
+
+// Serve listens on ServerConfig.Addr and serves NewAggregateHandler at POST /aggregate, NewJobsHandler at /jobs and,
+// if ServerConfig.Dataset is set, NewDatasetHandler at GET /dataset until ctx is done. Then it shuts the server down
+// gracefully, waiting up to ServerConfig.ShutdownTimeout for the requests in progress, and cancels the running jobs.
+// It returns an error if the job store cannot be created or the server cannot listen or shut down.
+func Serve(ctx context.Context, cfg ServerConfig) error {
+	cfg = cfg.withDefaults()
+	var store JobStore = NewMemoryJobStore(cfg.MaxEndedJobs)
+	if cfg.JobsDir != "" {
+		fs, err := NewFileJobStore(cfg.JobsDir)
+		if err != nil {
+			return err
+		}
+		store = fs
+	}
+	jobs := NewJobs(store)
+	defer jobs.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/aggregate", NewAggregateHandler(cfg))
+	jobsHandler := NewJobsHandler(cfg, jobs)
+	mux.Handle("/jobs", jobsHandler)
+	mux.Handle("/jobs/", jobsHandler)
+	if cfg.Dataset != nil {
+		mux.Handle("/dataset", NewDatasetHandler(cfg))
+	}
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+// NewAggregateHandler creates the handler that aggregates the records sent in the request body. The body is a JSON
+// array of records or NDJSON and the filter is set by the query parameters: postcode, timerange, names (comma
+// separated), match_mode, threshold, from, to, where, group_by (comma separated), postcode_group and raw. The response
+// is the Aggregation JSON.
+// The headers X-Records-Parsed and X-Records-Ignored have the Parse counts. Parsing stops once the request is
+// canceled, e.g. the client disconnects or the server shuts down, and nothing is responded.
+func NewAggregateHandler(cfg ServerConfig) http.Handler {
+	cfg = cfg.withDefaults()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+			return
+		}
+
+		filter, recipes, postcodes, err := cfg.requestFilter(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		calculator := NewSummaryCalculator(filter)
+		var calc Calculator = &calculator
+		if recipes != nil {
+			calc = NewNormalizingCalculator(calc, recipes, postcodes)
+		}
+
+		body := &io.LimitedReader{R: req.Body, N: cfg.MaxBodyBytes + 1}
+		ctx := req.Context()
+		parsed, ignored, err := parseRecords(ctx, body, DefaultSchema, calc, func(rc, pc, ic int) {})
+		if err != nil && err == ctx.Err() {
+			return
+		}
+		if body.N <= 0 {
+			writeError(w, http.StatusRequestEntityTooLarge,
+				fmt.Errorf("request body larger than %d bytes", cfg.MaxBodyBytes))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Records-Parsed", strconv.Itoa(parsed))
+		w.Header().Set("X-Records-Ignored", strconv.Itoa(ignored))
+		fmt.Fprintln(w, calculator.Aggregate())
+	})
+}
+
+// NewDatasetHandler creates the handler that aggregates ServerConfig.Dataset, which must be set, without reading a
+// body. The filter is set as in NewAggregateHandler, except postcode_group and raw, as the records of the Dataset are
+// already normalized. The response is the Aggregation JSON and the header X-Records-Parsed has the number of records.
+func NewDatasetHandler(cfg ServerConfig) http.Handler {
+	cfg = cfg.withDefaults()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+			return
+		}
+		for _, name := range []string{"postcode_group", "raw"} {
+			if req.URL.Query().Get(name) != "" {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("%s is not supported, the dataset is normalized", name))
+				return
+			}
+		}
+
+		filter, _, _, err := cfg.requestFilter(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Records-Parsed", strconv.Itoa(cfg.Dataset.Len()))
+		fmt.Fprintln(w, cfg.Dataset.Aggregate(filter))
+	})
+}
+
+// NewJobsHandler creates the handler of the job API:
+// - POST /jobs submits a job. The input is the file at the path query parameter, relative to ServerConfig.DataDir, or
+// the request body, a JSON array of records or NDJSON. The filter is set as in NewAggregateHandler. It responds
+// 202 with the JobStatus;
+// - GET /jobs/{id} responds the JobStatus;
+// - GET /jobs/{id}/result responds the Aggregation, or 409 if the job is not done;
+// - DELETE /jobs/{id} cancels the job and responds 202 with its JobStatus.
+func NewJobsHandler(cfg ServerConfig, jobs *Jobs) http.Handler {
+	cfg = cfg.withDefaults()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/jobs"), "/"), "/")
+		switch {
+		case parts[0] == "" && req.Method == http.MethodPost:
+			cfg.submitJob(w, req, jobs)
+		case parts[0] != "" && len(parts) == 1 && req.Method == http.MethodGet:
+			writeJobStatus(w, http.StatusOK, jobs, parts[0])
+		case parts[0] != "" && len(parts) == 1 && req.Method == http.MethodDelete:
+			if err := jobs.Cancel(parts[0]); err != nil {
+				writeJobError(w, err)
+				return
+			}
+			writeJobStatus(w, http.StatusAccepted, jobs, parts[0])
+		case len(parts) == 2 && parts[1] == "result" && req.Method == http.MethodGet:
+			job, err := jobs.Job(parts[0])
+			if err != nil {
+				writeJobError(w, err)
+				return
+			}
+			if job.State != JobDone {
+				writeError(w, http.StatusConflict, fmt.Errorf("job %s is %s", job.ID, job.State))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, job.Result)
+		default:
+			writeError(w, http.StatusNotFound, fmt.Errorf("%s %s not found", req.Method, req.URL.Path))
+		}
+	})
+}
+
+func (cfg ServerConfig) submitJob(w http.ResponseWriter, req *http.Request, jobs *Jobs) {
+	filter, recipes, postcodes, err := cfg.requestFilter(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var (
+		src  io.ReadCloser
+		size int64
+	)
+	if path := req.URL.Query().Get("path"); path != "" {
+		src, size, err = cfg.openDataFile(path)
+	} else {
+		src, size, err = cfg.saveUpload(req.Body)
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errUploadTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	status, err := jobs.Submit(src, size, filter, recipes, postcodes)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+status.ID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+// openDataFile opens path inside ServerConfig.DataDir. A path cannot leave DataDir.
+func (cfg ServerConfig) openDataFile(path string) (io.ReadCloser, int64, error) {
+	if cfg.DataDir == "" {
+		return nil, 0, fmt.Errorf("jobs cannot read files by path, the server has no data directory")
+	}
+
+	name := filepath.Join(cfg.DataDir, filepath.Clean("/"+path))
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error to read [file=%v]: %v", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, 0, fmt.Errorf("error to read [file=%v]: not a regular file", path)
+	}
+
+	return f, info.Size(), nil
+}
+
+// saveUpload copies body to a temporary file that is removed when it is closed.
+func (cfg ServerConfig) saveUpload(body io.Reader) (io.ReadCloser, int64, error) {
+	f, err := os.CreateTemp("", "recipe-count-job-*.json")
+	if err != nil {
+		return nil, 0, fmt.Errorf("error to save upload: %v", err)
+	}
+	upload := &tempFile{f}
+
+	size, err := io.Copy(f, &io.LimitedReader{R: body, N: cfg.MaxUploadBytes + 1})
+	if err == nil && size > cfg.MaxUploadBytes {
+		err = fmt.Errorf("%w, it is larger than %d bytes", errUploadTooLarge, cfg.MaxUploadBytes)
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		upload.Close()
+		return nil, 0, fmt.Errorf("error to save upload: %w", err)
+	}
+
+	return upload, size, nil
+}
+
+// Close closes and removes the temporary file.
+func (t *tempFile) Close() error {
+	err := t.File.Close()
+	if rmErr := os.Remove(t.Name()); err == nil {
+		err = rmErr
+	}
+
+	return err
+}
+
+func writeJobStatus(w http.ResponseWriter, code int, jobs *Jobs, id string) {
+	status, err := jobs.Status(id)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	writeJSON(w, code, status)
+}
+
+func writeJobError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrJobNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// filterParams are the filter values of a request. Empty values and a nil Threshold keep the defaults.
+type filterParams struct {
+	Postcode      string
+	TimeRange     string
+	Names         []string
+	MatchMode     string
+	Threshold     *float64
+	FromDate      string
+	ToDate        string
+	Where         string
+	GroupBy       []string
+	PostcodeGroup string
+	Raw           bool
+}
+
+// requestFilter builds the Filter of a request and its normalizers, which are nil if the raw query parameter is true.
+func (cfg ServerConfig) requestFilter(req *http.Request) (Filter, *RecipeNormalizer, *PostcodeNormalizer, error) {
+	q := req.URL.Query()
+	p := filterParams{
+		Postcode:      q.Get("postcode"),
+		TimeRange:     q.Get("timerange"),
+		MatchMode:     q.Get("match_mode"),
+		FromDate:      q.Get("from"),
+		ToDate:        q.Get("to"),
+		Where:         q.Get("where"),
+		PostcodeGroup: q.Get("postcode_group"),
+	}
+	if v := q.Get("names"); v != "" {
+		p.Names = strings.Split(v, ",")
+	}
+	if v := q.Get("group_by"); v != "" {
+		p.GroupBy = strings.Split(v, ",")
+	}
+	if v := q.Get("threshold"); v != "" {
+		th, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Filter{}, nil, nil, fmt.Errorf("invalid threshold %q: %v", v, err)
+		}
+		p.Threshold = &th
+	}
+	p.Raw, _ = strconv.ParseBool(q.Get("raw"))
+
+	return cfg.filter(p)
+}
+
+// filter builds the Filter of p over ServerConfig.DefaultFilter and its normalizers, which are nil if p.Raw is true.
+func (cfg ServerConfig) filter(p filterParams) (Filter, *RecipeNormalizer, *PostcodeNormalizer, error) {
+	filter := cfg.DefaultFilter
+	if p.Postcode != "" {
+		filter.Postcode = p.Postcode
+	}
+	if p.TimeRange != "" {
+		filter.TimeRange = p.TimeRange
+	}
+	if len(p.Names) > 0 {
+		filter.Recipes = p.Names
+	}
+	if p.MatchMode != "" {
+		filter.MatchMode = MatchMode(p.MatchMode)
+	}
+	if p.Threshold != nil {
+		filter.Threshold = *p.Threshold
+	}
+	if p.FromDate != "" {
+		filter.FromDate = p.FromDate
+	}
+	if p.ToDate != "" {
+		filter.ToDate = p.ToDate
+	}
+	if p.Where != "" {
+		filter.Where = p.Where
+	}
+	if len(p.GroupBy) > 0 {
+		filter.GroupBy = p.GroupBy
+	}
+	if err := filter.Validate(); err != nil {
+		return Filter{}, nil, nil, err
+	}
+	if err := filter.ValidateFields(DefaultSchema); err != nil {
+		return Filter{}, nil, nil, err
+	}
+
+	if p.Raw {
+		return filter, nil, nil, nil
+	}
+
+	prefix := 0
+	if p.PostcodeGroup != "" {
+		var err error
+		if prefix, err = ParsePostcodeGroup(p.PostcodeGroup); err != nil {
+			return Filter{}, nil, nil, err
+		}
+	}
+	postcodes := NewPostcodeNormalizer(prefix, cfg.Regions)
+	filter.Postcode = postcodes.Normalize(filter.Postcode)
+	filter.Where = postcodes.NormalizeWhere(filter.Where)
+	filter.PostcodeDistribution = prefix > 0 || len(cfg.Regions) > 0
+
+	return filter, NewRecipeNormalizer(cfg.Aliases), postcodes, nil
+}
+
+func (cfg ServerConfig) withDefaults() ServerConfig {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if cfg.MaxUploadBytes <= 0 {
+		cfg.MaxUploadBytes = DefaultMaxUploadBytes
+	}
+	if cfg.MaxEndedJobs <= 0 {
+		cfg.MaxEndedJobs = DefaultMaxEndedJobs
+	}
+	if cfg.ReadTimeout <= 0 {
+		cfg.ReadTimeout = DefaultReadTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = DefaultWriteTimeout
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if cfg.DefaultFilter.Threshold == 0 {
+		cfg.DefaultFilter.Threshold = DefaultFuzzyThreshold
+	}
+
+	return cfg
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
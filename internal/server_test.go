@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAggregateHandler(t *testing.T) {
+	cfg := ServerConfig{
+		MaxBodyBytes:  1024,
+		DefaultFilter: regularFilter,
+		Aliases:       map[string]string{"Creamy Chicken": "Creamy Dill Chicken"},
+	}
+	cases := []struct {
+		name        string
+		method      string
+		query       string
+		body        string
+		wantStatus  int
+		wantParsed  string
+		wantRecipes []RecipeCount
+		wantCount   PostcodeAndTimeCount
+	}{
+		{"JSON array", http.MethodPost, "", serverArrayBody, http.StatusOK, "3",
+			[]RecipeCount{{"Cherry Balsamic Pork Chops", 1}, {"Creamy Dill Chicken", 2}},
+			PostcodeAndTimeCount{"10120", "10AM", "3PM", 1}},
+		{"NDJSON", http.MethodPost, "", serverNDJSONBody, http.StatusOK, "3",
+			[]RecipeCount{{"Cherry Balsamic Pork Chops", 1}, {"Creamy Dill Chicken", 2}},
+			PostcodeAndTimeCount{"10120", "10AM", "3PM", 1}},
+		{"Query filter", http.MethodPost, "?postcode=10224&timerange=1AM+-+8PM&raw=true", serverArrayBody,
+			http.StatusOK, "3", []RecipeCount{{"Cherry Balsamic Pork Chops", 1}, {"Creamy Chicken", 1},
+				{"Creamy Dill Chicken", 1}}, PostcodeAndTimeCount{"10224", "1AM", "8PM", 2}},
+		{"Invalid method", http.MethodGet, "", "", http.StatusMethodNotAllowed, "", nil, PostcodeAndTimeCount{}},
+		{"Invalid filter", http.MethodPost, "?match_mode=regex&names=(", serverArrayBody, http.StatusBadRequest, "",
+			nil, PostcodeAndTimeCount{}},
+		{"Invalid time range", http.MethodPost, "?timerange=10AM+-+3AM", serverArrayBody, http.StatusBadRequest, "",
+			nil, PostcodeAndTimeCount{}},
+		{"Group by", http.MethodPost, "?group_by=postcode&raw=true", serverArrayBody, http.StatusOK, "3",
+			[]RecipeCount{{"Cherry Balsamic Pork Chops", 1}, {"Creamy Chicken", 1}, {"Creamy Dill Chicken", 1}},
+			PostcodeAndTimeCount{"10120", "10AM", "3PM", 1}},
+		{"Zero threshold", http.MethodPost, "?match_mode=fuzzy&threshold=0", serverArrayBody, http.StatusBadRequest, "", nil,
+			PostcodeAndTimeCount{}},
+		{"Invalid JSON", http.MethodPost, "", `[{"postcode": 10120}]`, http.StatusBadRequest, "", nil,
+			PostcodeAndTimeCount{}},
+		{"Body too large", http.MethodPost, "", "[" + strings.Repeat(" ", 1024) + "]",
+			http.StatusRequestEntityTooLarge, "", nil, PostcodeAndTimeCount{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(c.method, "/aggregate"+c.query, strings.NewReader(c.body))
+
+			NewAggregateHandler(cfg).ServeHTTP(rec, req)
+
+			if rec.Code != c.wantStatus || rec.Header().Get("X-Records-Parsed") != c.wantParsed {
+				t.Fatalf("%s, want: %v %v, got: %v %v %s", c.name, c.wantStatus, c.wantParsed, rec.Code,
+					rec.Header().Get("X-Records-Parsed"), rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var got Aggregation
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%s, unexpected error: %v", c.name, err)
+			}
+			if !reflect.DeepEqual(c.wantRecipes, got.RecipeCount) || c.wantCount != got.PostcodeAndTimeCount {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.wantRecipes, c.wantCount, got.RecipeCount,
+					got.PostcodeAndTimeCount)
+			}
+		})
+	}
+}
+
+const (
+	serverArrayBody = `[
+{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"},
+{"postcode": "10224", "recipe": "Creamy Chicken", "delivery": "Wednesday 1AM - 7PM"},
+{"postcode": "10120", "recipe": "Cherry Balsamic Pork Chops", "delivery": "Thursday 10AM - 2PM"},
+{"postcode": "10120", "recipe": "Cherry Balsamic Pork Chops", "delivery": ""}
+]`
+	serverNDJSONBody = `{"postcode": "10224", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"}
+{"postcode": "10224", "recipe": "Creamy Chicken", "delivery": "Wednesday 1AM - 7PM"}
+{"postcode": "10120", "recipe": "Cherry Balsamic Pork Chops", "delivery": "Thursday 10AM - 2PM"}
+{"postcode": "10120", "recipe": "Cherry Balsamic Pork Chops", "delivery": ""}
+`
+)
+
+func TestAggregateHandlerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/aggregate", strings.NewReader(serverNDJSONBody)).WithContext(ctx)
+
+	NewAggregateHandler(ServerConfig{DefaultFilter: regularFilter}).ServeHTTP(rec, req)
+
+	if rec.Header().Get("X-Records-Parsed") != "" || rec.Body.Len() != 0 {
+		t.Errorf("Canceled, want: %v, got: %v %s", "no response", rec.Header().Get("X-Records-Parsed"), rec.Body)
+	}
+}
+
+func TestDatasetHandler(t *testing.T) {
+	cfg := ServerConfig{
+		DefaultFilter: regularFilter,
+		Aliases:       map[string]string{"Creamy Chicken": "Creamy Dill Chicken"},
+		Dataset:       NewDataset(),
+	}
+	calc := NewNormalizingCalculator(cfg.Dataset, NewRecipeNormalizer(cfg.Aliases), NewPostcodeNormalizer(0, nil))
+	if _, _, err := ParseReader(strings.NewReader(serverNDJSONBody), calc, false); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name        string
+		method      string
+		query       string
+		wantStatus  int
+		wantRecipes []RecipeCount
+		wantCount   PostcodeAndTimeCount
+	}{
+		{"Default filter", http.MethodGet, "", http.StatusOK,
+			[]RecipeCount{{"Cherry Balsamic Pork Chops", 1}, {"Creamy Dill Chicken", 2}},
+			PostcodeAndTimeCount{"10120", "10AM", "3PM", 1}},
+		{"Query filter", http.MethodGet, "?postcode=10224&timerange=1AM+-+8PM&where=weekday+%3D+Wed", http.StatusOK,
+			[]RecipeCount{{"Creamy Dill Chicken", 2}}, PostcodeAndTimeCount{"10224", "1AM", "8PM", 2}},
+		{"Invalid method", http.MethodPost, "", http.StatusMethodNotAllowed, nil, PostcodeAndTimeCount{}},
+		{"Invalid filter", http.MethodGet, "?timerange=foo", http.StatusBadRequest, nil, PostcodeAndTimeCount{}},
+		{"Raw", http.MethodGet, "?raw=true", http.StatusBadRequest, nil, PostcodeAndTimeCount{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(c.method, "/dataset"+c.query, nil)
+
+			NewDatasetHandler(cfg).ServeHTTP(rec, req)
+
+			if rec.Code != c.wantStatus {
+				t.Fatalf("%s, want: %v, got: %v %s", c.name, c.wantStatus, rec.Code, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var got Aggregation
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("%s, unexpected error: %v", c.name, err)
+			}
+			if !reflect.DeepEqual(c.wantRecipes, got.RecipeCount) || c.wantCount != got.PostcodeAndTimeCount ||
+				rec.Header().Get("X-Records-Parsed") != "3" {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.wantRecipes, c.wantCount, got.RecipeCount,
+					got.PostcodeAndTimeCount)
+			}
+		})
+	}
+}
+
+func TestJobsHandler(t *testing.T) {
+	jobs := NewJobs(NewMemoryJobStore(0))
+	defer jobs.Close()
+	cfg := ServerConfig{MaxUploadBytes: 1024, DataDir: "../test", DefaultFilter: regularFilter}
+	handler := NewJobsHandler(cfg, jobs)
+
+	submit := func(query, body string) (int, JobStatus) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jobs"+query, strings.NewReader(body)))
+		var status JobStatus
+		json.Unmarshal(rec.Body.Bytes(), &status)
+		return rec.Code, status
+	}
+	_, upload := submit("?raw=true", serverArrayBody)
+	_, file := submit("?path=/hundred_line_sample.json", "")
+	jobs.Wait()
+
+	cases := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"Upload status", http.MethodGet, "/jobs/" + upload.ID, "", http.StatusOK, `"state":"done","records":4,` +
+			`"parsed":3,"ignored":1,"percent":100`},
+		{"Upload result", http.MethodGet, "/jobs/" + upload.ID + "/result", "", http.StatusOK,
+			`"unique_recipe_count": 3`},
+		{"File result", http.MethodGet, "/jobs/" + file.ID + "/result", "", http.StatusOK,
+			`"unique_recipe_count": 16`},
+		{"Cancel ended job", http.MethodDelete, "/jobs/" + file.ID, "", http.StatusAccepted, `"state":"done"`},
+		{"Unknown job", http.MethodGet, "/jobs/unknown", "", http.StatusNotFound, `"error":"job not found"`},
+		{"Unknown route", http.MethodGet, "/jobs", "", http.StatusNotFound, `"error"`},
+		{"Path outside data dir", http.MethodPost, "/jobs?path=../go.mod", "", http.StatusBadRequest, `"error"`},
+		{"Invalid filter", http.MethodPost, "/jobs?threshold=high", serverArrayBody, http.StatusBadRequest, `"error"`},
+		{"Upload too large", http.MethodPost, "/jobs", strings.Repeat(" ", 1025), http.StatusRequestEntityTooLarge,
+			`"error"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(c.method, c.target, strings.NewReader(c.body)))
+
+			if rec.Code != c.wantStatus || !strings.Contains(rec.Body.String(), c.wantBody) {
+				t.Errorf("%s, want: %v %v, got: %v %s", c.name, c.wantStatus, c.wantBody, rec.Code, rec.Body)
+			}
+		})
+	}
+}
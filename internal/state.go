@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// StateVersion is the version of the CalculatorState format. LoadState rejects states of other versions.
+const StateVersion = 1
+
+// CalculatorState is the state of a SummaryCalculator saved between runs, so new records are added to the ones
+// already calculated instead of calculating all of them again. Parsed and Ignored are the record counts so far.
+// The state keeps the recipe names and postcodes as they were calculated, so the same normalizers must be used to
+// add records to it. RecipeNames are the spellings of the RecipeNormalizer, see RecipeNormalizer.Names, which are
+// set and restored by the caller as the SummaryCalculator does not know its normalizers.
+type CalculatorState struct {
+	Version              int                  `json:"version"`
+	Filter               Filter               `json:"filter"`
+	Parsed               int                  `json:"parsed"`
+	Ignored              int                  `json:"ignored"`
+	Recipes              map[string]int       `json:"recipes"`
+	Postcodes            map[string]int       `json:"postcodes"`
+	Dates                map[string]int       `json:"dates,omitempty"`
+	Groups               map[string]int       `json:"groups,omitempty"`
+	PostcodeAndTimeCount PostcodeAndTimeCount `json:"count_per_postcode_and_time"`
+	NameMatches          []NameMatch          `json:"match_by_name"`
+	RecipeNames          []string             `json:"recipe_names,omitempty"`
+}
+
+// State returns the state of s. Parsed and Ignored are not known by s, so they are left to the caller.
+func (s SummaryCalculator) State() CalculatorState {
+	st := CalculatorState{
+		Version:              StateVersion,
+		Filter:               s.Filter,
+		Recipes:              make(map[string]int, len(s.uniqueRecipesCache)),
+		Postcodes:            make(map[string]int, len(s.busiestPostcode)),
+		PostcodeAndTimeCount: s.postcodeAndTimeCount,
+	}
+	for k, v := range s.uniqueRecipesCache {
+		st.Recipes[k] = v
+	}
+	for k, v := range s.busiestPostcode {
+		st.Postcodes[k] = v
+	}
+	if len(s.dateCounts) > 0 {
+		st.Dates = make(map[string]int, len(s.dateCounts))
+		for k, v := range s.dateCounts {
+			st.Dates[k] = v
+		}
+	}
+	if len(s.groupCounts) > 0 {
+		st.Groups = make(map[string]int, len(s.groupCounts))
+		for k, v := range s.groupCounts {
+			st.Groups[k] = v
+		}
+	}
+	st.NameMatches, _ = s.sumNameMatches()
+
+	return st
+}
+
+// RestoreSummaryCalculator creates a SummaryCalculator from st that goes on calculating records with filter.
+// It returns an error if st has another version or was calculated with another filter, since its Aggregation would
+// differ from the one of all records calculated at once.
+func RestoreSummaryCalculator(filter Filter, st CalculatorState) (SummaryCalculator, error) {
+	if st.Version != StateVersion {
+		return SummaryCalculator{}, fmt.Errorf("unsupported state version %d, want %d", st.Version, StateVersion)
+	}
+	if !sameFilter(filter, st.Filter) {
+		return SummaryCalculator{}, fmt.Errorf("state was calculated with another filter: %v", st.Filter)
+	}
+
+	s := NewSummaryCalculator(filter)
+	for k, v := range st.Recipes {
+		s.uniqueRecipesCache[k] = v
+	}
+	for k, v := range st.Postcodes {
+		s.busiestPostcode[k] = v
+	}
+	for k, v := range st.Dates {
+		s.dateCounts[k] = v
+	}
+	for k, v := range st.Groups {
+		s.groupCounts[k] = v
+	}
+	s.postcodeAndTimeCount = st.PostcodeAndTimeCount
+	for _, nm := range st.NameMatches {
+		s.nameMatchDetails[nm.Recipe] = nm
+		s.nameMatchesCache = append(s.nameMatchesCache, nm.Recipe)
+	}
+	sort.Strings(s.nameMatchesCache)
+
+	return s, nil
+}
+
+// LoadState reads a CalculatorState saved by SaveState.
+// It returns an error if the file cannot be read or decoded.
+func LoadState(path string) (CalculatorState, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return CalculatorState{}, fmt.Errorf("error to read state [file=%v]: %v", path, err)
+	}
+
+	var st CalculatorState
+	if err := json.Unmarshal(content, &st); err != nil {
+		return CalculatorState{}, fmt.Errorf("error to decode state [file=%v]: %v", path, err)
+	}
+
+	return st, nil
+}
+
+// SaveState writes st as JSON to path. It writes a temporary file first, so path keeps the previous state if the
+// process stops while writing.
+// It returns an error if the file cannot be written.
+func SaveState(path string, st CalculatorState) error {
+	return saveJSON(path, "state", st)
+}
+
+// saveJSON writes v, named what in the errors, as JSON to a temporary file and renames it to path.
+func saveJSON(path, what string, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error to encode %s [file=%v]: %v", what, path, err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("error to write %s [file=%v]: %v", what, tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error to write %s [file=%v]: %v", what, path, err)
+	}
+
+	return nil
+}
+
+// sameFilter compares the filters ignoring an empty Recipes against a nil one, which JSON does not keep apart.
+func sameFilter(a, b Filter) bool {
+	if len(a.Recipes) == 0 && len(b.Recipes) == 0 {
+		a.Recipes, b.Recipes = nil, nil
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+const sampleFile = "../test/hundred_line_sample.json"
+
+func TestRestoreSummaryCalculator(t *testing.T) {
+	records := readSample(t)
+	full := NewSummaryCalculator(regularFilter)
+	for _, r := range records {
+		full.Calculate(r)
+	}
+	want := full.Aggregate()
+
+	for _, split := range []int{0, 1, len(records) / 2, len(records)} {
+		previous := NewSummaryCalculator(regularFilter)
+		for _, r := range records[:split] {
+			previous.Calculate(r)
+		}
+		if err := SaveState(stubFile, previous.State()); err != nil {
+			t.Fatalf("SaveState, want: %v, got: %v", nil, err)
+		}
+		st, err := LoadState(stubFile)
+		removeFile()
+		if err != nil {
+			t.Fatalf("LoadState, want: %v, got: %v", nil, err)
+		}
+
+		calc, err := RestoreSummaryCalculator(regularFilter, st)
+		if err != nil {
+			t.Fatalf("RestoreSummaryCalculator, want: %v, got: %v", nil, err)
+		}
+		for _, r := range records[split:] {
+			calc.Calculate(r)
+		}
+
+		if got := calc.Aggregate(); !reflect.DeepEqual(want, got) {
+			t.Errorf("Split at %d, want: %v, got: %v", split, want, got)
+		}
+	}
+}
+
+// TestRestoreSummaryCalculatorRecipeNames checks that a run restored from a state spells the recipes as the previous
+// runs did, as a single run over all the files does.
+func TestRestoreSummaryCalculatorRecipeNames(t *testing.T) {
+	files := [][]Record{
+		{{Postcode: "10120", Recipe: "Tex-Mex Tilapia", Delivery: "Wednesday 1AM - 7PM"}},
+		{{Postcode: "10120", Recipe: "tex-mex  tilapia", Delivery: "Wednesday 1AM - 7PM"}},
+	}
+
+	combined := NewSummaryCalculator(regularFilter)
+	calc := NewNormalizingCalculator(&combined, NewRecipeNormalizer(nil), NewPostcodeNormalizer(0, nil))
+	for _, records := range files {
+		for _, r := range records {
+			calc.Calculate(r)
+		}
+	}
+	want := combined.Aggregate()
+
+	st := NewSummaryCalculator(regularFilter).State()
+	var got Aggregation
+	for _, records := range files {
+		calculator, err := RestoreSummaryCalculator(regularFilter, st)
+		if err != nil {
+			t.Fatalf("RestoreSummaryCalculator, want: %v, got: %v", nil, err)
+		}
+		recipes := NewRecipeNormalizer(nil)
+		recipes.AddNames(st.RecipeNames)
+		calc := NewNormalizingCalculator(&calculator, recipes, NewPostcodeNormalizer(0, nil))
+		for _, r := range records {
+			calc.Calculate(r)
+		}
+
+		next := calculator.State()
+		next.RecipeNames = recipes.Names()
+		if err := SaveState(stubFile, next); err != nil {
+			t.Fatalf("SaveState, want: %v, got: %v", nil, err)
+		}
+		st, err = LoadState(stubFile)
+		removeFile()
+		if err != nil {
+			t.Fatalf("LoadState, want: %v, got: %v", nil, err)
+		}
+		got = calculator.Aggregate()
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+	if want := []string{"Tex-Mex Tilapia"}; !reflect.DeepEqual(want, st.RecipeNames) {
+		t.Errorf("RecipeNames, want: %v, got: %v", want, st.RecipeNames)
+	}
+}
+
+func TestRestoreSummaryCalculatorErrors(t *testing.T) {
+	otherFilter := regularFilter
+	otherFilter.Postcode = "10224"
+	oldState := NewSummaryCalculator(regularFilter).State()
+	oldState.Version = StateVersion - 1
+
+	cases := []struct {
+		name   string
+		filter Filter
+		state  CalculatorState
+	}{
+		{"Another filter", otherFilter, NewSummaryCalculator(regularFilter).State()},
+		{"Another version", regularFilter, oldState},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := RestoreSummaryCalculator(c.filter, c.state); err == nil {
+				t.Errorf("%s, want: an error, got: %v", c.name, err)
+			}
+		})
+	}
+}
+
+func readSample(t *testing.T) []Record {
+	f, err := os.Open(sampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []Record
+	err = decodeRecords(f, DefaultSchema, func(i int, r *Record) {
+		if r.IsValid() {
+			records = append(records, *r)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return records
+}
@@ -0,0 +1,56 @@
+package internal
+
+type (
+	// Stats are quick counts of an input. Records, Valid and Invalid are the Parse counts and the other fields count
+	// the valid records.
+	Stats struct {
+		Records              int            `json:"records"`
+		Valid                int            `json:"valid"`
+		Invalid              int            `json:"invalid"`
+		UniqueRecipes        int            `json:"unique_recipe_count"`
+		UniquePostcodes      int            `json:"unique_postcode_count"`
+		DeliveriesPerWeekday map[string]int `json:"deliveries_per_weekday"`
+	}
+	// StatsCalculator is a Calculator that only counts distinct recipes, distinct postcodes and deliveries per
+	// weekday. It is much cheaper than SummaryCalculator, which also sorts and filters.
+	StatsCalculator struct {
+		recipes   map[string]struct{}
+		postcodes map[string]struct{}
+		weekdays  map[string]int
+	}
+)
+
+// NewStatsCalculator creates an empty StatsCalculator.
+func NewStatsCalculator() *StatsCalculator {
+	return &StatsCalculator{
+		recipes:   make(map[string]struct{}),
+		postcodes: make(map[string]struct{}),
+		weekdays:  make(map[string]int),
+	}
+}
+
+// Calculate counts r.
+func (s *StatsCalculator) Calculate(r Record) {
+	s.recipes[r.Recipe] = struct{}{}
+	s.postcodes[r.Postcode] = struct{}{}
+	if w, ok := r.deliveryWindow(); ok {
+		s.weekdays[w.Weekday]++
+	}
+}
+
+// Stats returns the counts given the parsed and ignored counts returned by Parse.
+func (s *StatsCalculator) Stats(parsed, ignored int) Stats {
+	weekdays := make(map[string]int, len(s.weekdays))
+	for weekday, count := range s.weekdays {
+		weekdays[weekday] = count
+	}
+
+	return Stats{
+		Records:              parsed + ignored,
+		Valid:                parsed,
+		Invalid:              ignored,
+		UniqueRecipes:        len(s.recipes),
+		UniquePostcodes:      len(s.postcodes),
+		DeliveriesPerWeekday: weekdays,
+	}
+}
@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStatsCalculator(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want Stats
+	}{
+		{"Counts", serverArrayBody, Stats{4, 3, 1, 3, 2, map[string]int{"Wednesday": 2, "Thursday": 1}}},
+		{"Empty", "[]", Stats{0, 0, 0, 0, 0, map[string]int{}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calc := NewStatsCalculator()
+			parsed, ignored, _ := ParseReader(strings.NewReader(c.in), calc, false)
+
+			if got := calc.Stats(parsed, ignored); !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
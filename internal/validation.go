@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"time"
+	"unicode"
+)
+
+type (
+	// RecordProblem is an invalid record: its index in the input, starting at 1, and why it is invalid.
+	RecordProblem struct {
+		Record int    `json:"record"`
+		Error  string `json:"error"`
+	}
+	// ValidationReport summarizes the records of an input. Problems has the first invalid records, up to the limit
+	// given to Validate.
+	ValidationReport struct {
+		Records  int             `json:"records"`
+		Valid    int             `json:"valid"`
+		Invalid  int             `json:"invalid"`
+		Problems []RecordProblem `json:"problems"`
+	}
+)
+
+// Validate checks the records read from r, a JSON array or a stream of records decoded with schema, without
+// aggregating them. At most maxProblems problems are reported; a negative maxProblems reports all of them.
+// It returns the report of the records decoded so far and an error if the input is not a valid JSON.
+func Validate(r io.Reader, schema Schema, maxProblems int) (ValidationReport, error) {
+	report := ValidationReport{Problems: []RecordProblem{}}
+	err := decodeRecords(r, schema, func(i int, r *Record) {
+		report.Records = i
+		if err := r.Validate(); err != nil {
+			report.Invalid++
+			if maxProblems < 0 || len(report.Problems) < maxProblems {
+				report.Problems = append(report.Problems, RecordProblem{i, err.Error()})
+			}
+			return
+		}
+		report.Valid++
+	})
+
+	return report, err
+}
+
+// FilterError is an invalid value of a Filter field. Offset is the byte offset in Value where the problem starts.
+type FilterError struct {
+	Field  string
+	Value  string
+	Offset int
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("invalid %s %q at position %d: %s", e.Field, e.Value, e.Offset+1, e.Reason)
+}
+
+// ValidatePostcode checks that postcode follows the rules of Record.Postcode: 1 to 10 characters. Only letters,
+// digits, spaces and hyphens are accepted.
+// It returns a *FilterError otherwise.
+func ValidatePostcode(postcode string) error {
+	const maxCharacterPostcodeAllowed = 10
+	switch {
+	case postcode == "":
+		return &FilterError{"postcode", postcode, 0, "it must not be empty"}
+	case len(postcode) > maxCharacterPostcodeAllowed:
+		return &FilterError{"postcode", postcode, maxCharacterPostcodeAllowed,
+			fmt.Sprintf("it must have at most %d characters", maxCharacterPostcodeAllowed)}
+	}
+
+	for i, c := range postcode {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != ' ' && c != '-' {
+			return &FilterError{"postcode", postcode, i, fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return nil
+}
+
+// ValidateTimeRange checks that timeRange has what parseTimeRange reads from it: a begin hour followed by AM and an
+// end hour followed by PM, where the hours are 1 to 12. Any other text is accepted as parseTimeRange ignores it, so
+// "10AM - 3PM", "10AM-3PM" and "Friday 10AM - 2PM" are valid.
+// It returns a *FilterError pointing at the start of timeRange if the begin hour is missing, or after the begin hour
+// if the end hour is missing.
+func ValidateTimeRange(timeRange string) error {
+	begin := beginHourRegex.FindStringIndex(timeRange)
+	if begin == nil {
+		return &FilterError{"time range", timeRange, 0, `expected a begin hour followed by AM, e.g. "10AM - 3PM"`}
+	}
+	if !endHourRegex.MatchString(timeRange) {
+		return &FilterError{"time range", timeRange, begin[1], `expected an end hour followed by PM, e.g. "10AM - 3PM"`}
+	}
+
+	return nil
+}
+
+// ValidateDate checks that date, the field of a date range, is empty or an ISO 8601 date: "YYYY-MM-DD".
+// It returns a *FilterError pointing at the first unexpected character otherwise.
+func ValidateDate(field, date string) error {
+	if date == "" {
+		return nil
+	}
+	if i, reason := checkDate(date); i >= 0 {
+		return &FilterError{field, date, i, reason}
+	}
+	if len(date) > 10 {
+		return &FilterError{field, date, 10, "unexpected text after the date"}
+	}
+
+	return nil
+}
+
+// checkDate checks "YYYY-MM-DD" at the start of s. It returns the index of the first invalid character and why it is
+// invalid, or -1 if the date is valid.
+func checkDate(s string) (int, string) {
+	for i := 0; i < 10; i++ {
+		switch {
+		case i >= len(s):
+			return i, `expected a date in the format "YYYY-MM-DD"`
+		case i == 4 || i == 7:
+			if s[i] != '-' {
+				return i, "expected a dash"
+			}
+		case !isDigit(s[i]):
+			return i, "expected a digit"
+		}
+	}
+
+	month, day := atoi(s[5:7]), atoi(s[8:10])
+	if month < 1 || month > 12 {
+		return 5, fmt.Sprintf("month must be 1 to 12, got %d", month)
+	}
+	if days := time.Date(atoi(s[:4]), time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day(); day < 1 || day > days {
+		return 8, fmt.Sprintf("day must be 1 to %d, got %d", days, day)
+	}
+
+	return -1, ""
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name        string
+		in          string
+		maxProblems int
+		want        ValidationReport
+		wantErr     bool
+	}{
+		{"All problems", serverArrayBody, -1, ValidationReport{4, 3, 1, []RecordProblem{
+			{4, `delivery "" does not match the format "{Weekday} {H}AM - {H}PM"`}}}, false},
+		{"Limited problems", `{"postcode": "10120", "recipe": "", "delivery": "Wednesday 1AM - 7PM"}
+{"postcode": "", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"}`, 1,
+			ValidationReport{2, 0, 2, []RecordProblem{{1, "recipe must have 1 to 100 characters, got 0"}}}, false},
+		{"Invalid JSON", `[{"postcode": "10120", "recipe": "Creamy Dill Chicken", "delivery": "Wednesday 1AM - 7PM"},
+{"postcode": 10120}]`, -1, ValidationReport{1, 1, 0, []RecordProblem{}}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Validate(strings.NewReader(c.in), DefaultSchema, c.maxProblems)
+
+			if !reflect.DeepEqual(c.want, got) || (err != nil) != c.wantErr {
+				t.Errorf("%s, want: %v %v, got: %v %v", c.name, c.want, c.wantErr, got, err)
+			}
+		})
+	}
+}
+
+func TestValidateTimeRange(t *testing.T) {
+	cases := []struct {
+		name      string
+		timeRange string
+		want      error
+	}{
+		{"Hours", "10AM - 3PM", nil},
+		{"Weekday and lower case", "Friday 9am  -  12pm", nil},
+		{"No spaces around dash", "10AM-3PM", nil},
+		{"Empty", "", &FilterError{"time range", "", 0, `expected a begin hour followed by AM, e.g. "10AM - 3PM"`}},
+		{"Hour out of range", "0AM - 3PM", &FilterError{"time range", "0AM - 3PM", 0,
+			`expected a begin hour followed by AM, e.g. "10AM - 3PM"`}},
+		{"Missing meridiem", "10 - 3PM", &FilterError{"time range", "10 - 3PM", 0,
+			`expected a begin hour followed by AM, e.g. "10AM - 3PM"`}},
+		{"Missing end hour", "Friday 10AM - 3AM", &FilterError{"time range", "Friday 10AM - 3AM", 11,
+			`expected an end hour followed by PM, e.g. "10AM - 3PM"`}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ValidateTimeRange(c.timeRange); !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+// TestValidateTimeRangeParse checks that ValidateTimeRange accepts the time ranges that parseTimeRange parses.
+func TestValidateTimeRangeParse(t *testing.T) {
+	timeRanges := []string{"10AM - 3PM", "10AM-3PM", "Friday 10AM - 2PM", "9am - 12pm", "10AM 3PM", "3PM - 10AM",
+		"10AM - 3PM daily", " 10AM - 3PM", "010AM - 03PM", "", "foo", "10AM", "3PM", "10AM - 3AM", "13AM - 15PM",
+		"0AM - 0PM", "10 - 3PM"}
+
+	for _, timeRange := range timeRanges {
+		t.Run(timeRange, func(t *testing.T) {
+			_, parseErr := parseTimeRange(timeRange)
+			if err := ValidateTimeRange(timeRange); (err == nil) != (parseErr == nil) {
+				t.Errorf("%q, validate: %v, parse: %v", timeRange, err, parseErr)
+			}
+		})
+	}
+}
+
+func TestValidateDate(t *testing.T) {
+	cases := []struct {
+		name string
+		date string
+		want error
+	}{
+		{"Date", "2026-10-01", nil},
+		{"Empty", "", nil},
+		{"Leap day", "2028-02-29", nil},
+		{"Too short", "2026-10", &FilterError{"from date", "2026-10", 7, `expected a date in the format "YYYY-MM-DD"`}},
+		{"Missing dash", "2026/10/01", &FilterError{"from date", "2026/10/01", 4, "expected a dash"}},
+		{"Letter", "2026-1O-01", &FilterError{"from date", "2026-1O-01", 6, "expected a digit"}},
+		{"Month out of range", "2026-00-01", &FilterError{"from date", "2026-00-01", 5,
+			"month must be 1 to 12, got 0"}},
+		{"Day out of range", "2026-02-29", &FilterError{"from date", "2026-02-29", 8, "day must be 1 to 28, got 29"}},
+		{"Time", "2026-10-01T10:00:00Z", &FilterError{"from date", "2026-10-01T10:00:00Z", 10,
+			"unexpected text after the date"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ValidateDate("from date", c.date); !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestValidatePostcode(t *testing.T) {
+	cases := []struct {
+		name     string
+		postcode string
+		want     error
+	}{
+		{"Digits", "10120", nil},
+		{"Letters, space and hyphen", "SW1A 1-AA", nil},
+		{"Empty", "", &FilterError{"postcode", "", 0, "it must not be empty"}},
+		{"Too long", "10120101201", &FilterError{"postcode", "10120101201", 10, "it must have at most 10 characters"}},
+		{"Invalid character", "101;20", &FilterError{"postcode", "101;20", 3, "unexpected character ';'"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ValidatePostcode(c.postcode); !reflect.DeepEqual(c.want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,590 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a condition on a Record parsed by ParseWhere.
+type Expr interface {
+	// Match tells if r meets the condition.
+	Match(r Record) bool
+	// String returns the condition with all of its subexpressions between parentheses, so its precedence is explicit.
+	String() string
+	// fields calls fn with the field of each comparison.
+	fields(fn func(field string))
+}
+
+type (
+	// orExpr matches the records that match left or right.
+	orExpr struct{ left, right Expr }
+	// andExpr matches the records that match both left and right.
+	andExpr struct{ left, right Expr }
+	// notExpr matches the records that do not match expr.
+	notExpr struct{ expr Expr }
+	// compareExpr compares a field to value with op: =, !=, <, <=, >, >=, STARTS WITH, ENDS WITH or CONTAINS. The
+	// order operators compare numbers if value is an unquoted number, otherwise strings. If weekday is set, = and !=
+	// compare the weekdays by canonicalWeekday, so "Sat" is "Saturday" and "saturday" as well.
+	compareExpr struct {
+		field    string
+		op       string
+		value    string
+		number   float64
+		isNumber bool
+		weekday  bool
+	}
+	// inExpr matches the records whose field is one of values, or none of them if negate is set. Weekdays are
+	// compared as in compareExpr.
+	inExpr struct {
+		field   string
+		values  []string
+		negate  bool
+		weekday bool
+	}
+	// regexExpr matches the records whose field matches re, or does not if negate is set.
+	regexExpr struct {
+		field  string
+		re     *regexp.Regexp
+		negate bool
+	}
+)
+
+// ParseWhere parses a condition on the records. A comparison is a field, an operator and a value. E.g.
+// "postcode starts with 101 AND weekday in (Sat, Sun) AND recipe !~ 'Chicken'". The operators are:
+//   - = (or ==), !=, <, <=, >, >=; the order operators compare numbers if the value is an unquoted number;
+//   - STARTS WITH, ENDS WITH and CONTAINS;
+//   - ~ and !~, which match a regular expression;
+//   - IN and NOT IN, followed by a list of values between parentheses.
+//
+// Comparisons are combined by NOT (or !), AND (or &&) and OR (or ||), from the highest precedence to the lowest, and
+// grouped by parentheses. Keywords are case insensitive. Values are words, such as 101 or Sat, or quoted with single
+// or double quotes. The fields are the ones of Record.Field: postcode, recipe, delivery, delivery_date, weekday,
+// from_hour, to_hour and attributes. Weekdays might be abbreviated to their first three letters.
+// It returns a nil Expr for an empty where and a *FilterError pointing at the first unexpected token otherwise.
+func ParseWhere(where string) (Expr, error) {
+	if strings.TrimSpace(where) == "" {
+		return nil, nil
+	}
+
+	tokens, err := lexWhere("where", where)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &whereParser{name: "where", where: where, tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.fail(t, fmt.Sprintf("unexpected %v, expected AND or OR", t))
+	}
+
+	return e, nil
+}
+
+// normalizeWhere returns where with the values compared to the postcode by =, != and IN replaced by normalize of them.
+// The rest of where is kept as it is, and so is an invalid where, which ParseWhere reports.
+func normalizeWhere(where string, normalize func(string) string) string {
+	tokens, err := lexWhere("where", where)
+	if err != nil {
+		return where
+	}
+	p := &whereParser{name: "where", where: where, tokens: tokens}
+	if _, err := p.parseOr(); err != nil || p.peek().kind != tokenEOF {
+		return where
+	}
+
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, t := range p.postcodes {
+		value := normalize(t.text)
+		if value == t.text {
+			continue
+		}
+		end := t.offset + len(t.text)
+		if t.kind == tokenString {
+			_, n, _ := unquoteWhere(p.name, where, t.offset)
+			end = t.offset + n
+		}
+		b.WriteString(where[last:t.offset])
+		b.WriteString(`"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`)
+		last = end
+	}
+	b.WriteString(where[last:])
+
+	return b.String()
+}
+
+// whereFields calls fn with each field of e, which might be nil.
+func whereFields(e Expr, fn func(field string)) {
+	if e != nil {
+		e.fields(fn)
+	}
+}
+
+type (
+	tokenKind int
+	// whereToken is a token of a where and its byte offset.
+	whereToken struct {
+		kind   tokenKind
+		text   string
+		offset int
+	}
+	// whereParser parses the tokens of where. name is the name of where in errors and aliases are other names of the
+	// fields, e.g. from for from_hour. postcodes are the value tokens compared to the postcode by =, != and IN.
+	whereParser struct {
+		name      string
+		where     string
+		tokens    []whereToken
+		pos       int
+		aliases   map[string]string
+		postcodes []whereToken
+	}
+)
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+// whereOperators are the operators of a where, the ones of two characters first, and the * of a query.
+var whereOperators = []string{"==", "!=", "<=", ">=", "!~", "&&", "||", "=", "<", ">", "~", "!", "*"}
+
+// lexWhere splits where into tokens, ending with a tokenEOF. name is the name of where in errors.
+func lexWhere(name, where string) ([]whereToken, error) {
+	var tokens []whereToken
+	for i := 0; i < len(where); {
+		c := where[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, whereToken{tokenOpen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, whereToken{tokenClose, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, whereToken{tokenComma, ",", i})
+			i++
+		case c == '\'' || c == '"':
+			s, n, err := unquoteWhere(name, where, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, whereToken{tokenString, s, i})
+			i += n
+		case isWordByte(where[i]):
+			start := i
+			for i < len(where) && isWordByte(where[i]) {
+				i++
+			}
+			tokens = append(tokens, whereToken{tokenWord, where[start:i], start})
+		default:
+			op := ""
+			for _, o := range whereOperators {
+				if strings.HasPrefix(where[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, &FilterError{name, where, i, fmt.Sprintf("unexpected character %q", c)}
+			}
+			tokens = append(tokens, whereToken{tokenOperator, op, i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, whereToken{tokenEOF, "end of the condition", len(where)}), nil
+}
+
+// isWordByte tells if c is part of a word: letters, digits, underscores, hyphens, dots, colons and any non-ASCII byte.
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || c == '-' || c == '.' || c == ':' || unicode.IsLetter(rune(c)) || isDigit(c)
+}
+
+// unquoteWhere reads the string quoted at i of where. A backslash escapes the next character. It returns the string
+// and the length of its quoted form.
+func unquoteWhere(name, where string, i int) (string, int, error) {
+	var (
+		b     strings.Builder
+		quote = where[i]
+	)
+	for j := i + 1; j < len(where); j++ {
+		switch c := where[j]; {
+		case c == '\\' && j+1 < len(where):
+			j++
+			b.WriteByte(where[j])
+		case c == quote:
+			return b.String(), j + 1 - i, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, &FilterError{name, where, i, "unterminated string"}
+}
+
+// String returns the text of t quoted, as error messages show it. The text of a tokenEOF is not quoted.
+func (t whereToken) String() string {
+	if t.kind == tokenEOF {
+		return t.text
+	}
+
+	return strconv.Quote(t.text)
+}
+
+func (p *whereParser) peek() whereToken {
+	return p.tokens[p.pos]
+}
+
+func (p *whereParser) next() whereToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *whereParser) fail(t whereToken, reason string) error {
+	return &FilterError{p.name, p.where, t.offset, reason}
+}
+
+// field returns the field named name, or the one it is an alias of.
+func (p *whereParser) field(name string) string {
+	if field, ok := p.aliases[name]; ok {
+		return field
+	}
+
+	return name
+}
+
+func (p *whereParser) isKeyword(t whereToken, keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *whereParser) isOperator(t whereToken, ops ...string) bool {
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *whereParser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); p.isKeyword(t, "OR") || p.isOperator(t, "||"); t = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); p.isKeyword(t, "AND") || p.isOperator(t, "&&"); t = p.peek() {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *whereParser) parseNot() (Expr, error) {
+	if t := p.peek(); p.isKeyword(t, "NOT") || p.isOperator(t, "!") {
+		p.next()
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *whereParser) parsePrimary() (Expr, error) {
+	t := p.next()
+	if t.kind == tokenOpen {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.kind != tokenClose {
+			return nil, p.fail(c, fmt.Sprintf("unexpected %v, expected a closing parenthesis", c))
+		}
+		return e, nil
+	}
+
+	if t.kind != tokenWord || isWhereKeyword(t.text) {
+		return nil, p.fail(t, fmt.Sprintf("unexpected %v, expected a field, e.g. postcode", t))
+	}
+
+	return p.parseComparison(p.field(t.text))
+}
+
+func (p *whereParser) parseComparison(field string) (Expr, error) {
+	t := p.next()
+	switch {
+	case p.isOperator(t, "~", "!~"):
+		v, err := p.parseValue(field, false)
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(v.text)
+		if err != nil {
+			return nil, p.fail(v, fmt.Sprintf("invalid regular expression: %v", err))
+		}
+		return regexExpr{field, re, t.text == "!~"}, nil
+	case p.isOperator(t, "=", "==", "!=", "<", "<=", ">", ">="):
+		op := t.text
+		if op == "==" {
+			op = "="
+		}
+		return p.parseCompare(field, op, field == "weekday" && (op == "=" || op == "!="))
+	case p.isKeyword(t, "IN"):
+		return p.parseIn(field, false)
+	case p.isKeyword(t, "NOT"):
+		if in := p.next(); !p.isKeyword(in, "IN") {
+			return nil, p.fail(in, fmt.Sprintf("unexpected %v, expected IN after NOT", in))
+		}
+		return p.parseIn(field, true)
+	case p.isKeyword(t, "STARTS"), p.isKeyword(t, "ENDS"):
+		if with := p.next(); !p.isKeyword(with, "WITH") {
+			return nil, p.fail(with, fmt.Sprintf("unexpected %v, expected WITH after %s", with, t.text))
+		}
+		return p.parseCompare(field, strings.ToUpper(t.text)+" WITH", false)
+	case p.isKeyword(t, "CONTAINS"):
+		return p.parseCompare(field, "CONTAINS", false)
+	default:
+		return nil, p.fail(t, fmt.Sprintf("unexpected %v, expected an operator after %s, e.g. = or IN", t,
+			field))
+	}
+}
+
+func (p *whereParser) parseCompare(field string, op string, weekday bool) (Expr, error) {
+	v, err := p.parseValue(field, weekday)
+	if err != nil {
+		return nil, err
+	}
+
+	if field == "postcode" && (op == "=" || op == "!=") {
+		p.postcodes = append(p.postcodes, v)
+	}
+	c := compareExpr{field: field, op: op, value: v.text, weekday: weekday}
+	if n, err := strconv.ParseFloat(v.text, 64); err == nil && v.kind == tokenWord {
+		c.number, c.isNumber = n, true
+	}
+
+	return c, nil
+}
+
+func (p *whereParser) parseIn(field string, negate bool) (Expr, error) {
+	if t := p.next(); t.kind != tokenOpen {
+		return nil, p.fail(t, fmt.Sprintf("unexpected %v, expected a list of values, e.g. (Sat, Sun)", t))
+	}
+
+	in := inExpr{field: field, negate: negate, weekday: field == "weekday"}
+	for {
+		v, err := p.parseValue(field, in.weekday)
+		if err != nil {
+			return nil, err
+		}
+		in.values = append(in.values, v.text)
+		if field == "postcode" {
+			p.postcodes = append(p.postcodes, v)
+		}
+
+		switch t := p.next(); t.kind {
+		case tokenComma:
+		case tokenClose:
+			return in, nil
+		default:
+			return nil, p.fail(t, fmt.Sprintf("unexpected %v, expected a comma or a closing parenthesis", t))
+		}
+	}
+}
+
+// parseValue reads a word or a quoted string. If weekday is set, the value is replaced by its canonicalWeekday.
+func (p *whereParser) parseValue(field string, weekday bool) (whereToken, error) {
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return t, p.fail(t, fmt.Sprintf("unexpected %v, expected a value after %s", t, field))
+	}
+	if weekday {
+		day, ok := canonicalWeekday(t.text)
+		if !ok {
+			return t, p.fail(t, fmt.Sprintf("unknown weekday %q, e.g. Saturday or Sat", t.text))
+		}
+		t.text = day
+	}
+
+	return t, nil
+}
+
+func isWhereKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "AND", "OR", "NOT", "IN", "STARTS", "ENDS", "WITH", "CONTAINS":
+		return true
+	default:
+		return false
+	}
+}
+
+// canonicalWeekday returns the first three letters of a weekday in lower case. E.g. "Saturday" and "SAT" are "sat".
+// It returns false if day is neither a weekday nor its abbreviation.
+func canonicalWeekday(day string) (string, bool) {
+	day = strings.ToLower(day)
+	for _, d := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		if day == d || day == d[:3] {
+			return d[:3], true
+		}
+	}
+
+	return day, false
+}
+
+// whereValue returns the value of field of r, by its canonicalWeekday if weekday is set.
+func whereValue(r Record, field string, weekday bool) string {
+	v := r.Field(field)
+	if weekday {
+		v, _ = canonicalWeekday(v)
+	}
+
+	return v
+}
+
+func (e orExpr) Match(r Record) bool  { return e.left.Match(r) || e.right.Match(r) }
+func (e andExpr) Match(r Record) bool { return e.left.Match(r) && e.right.Match(r) }
+func (e notExpr) Match(r Record) bool { return !e.expr.Match(r) }
+
+func (e compareExpr) Match(r Record) bool {
+	v := whereValue(r, e.field, e.weekday)
+	switch e.op {
+	case "=":
+		return v == e.value
+	case "!=":
+		return v != e.value
+	case "STARTS WITH":
+		return strings.HasPrefix(v, e.value)
+	case "ENDS WITH":
+		return strings.HasSuffix(v, e.value)
+	case "CONTAINS":
+		return strings.Contains(v, e.value)
+	}
+
+	cmp := strings.Compare(v, e.value)
+	if e.isNumber {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return false
+		}
+		cmp = compareNumbers(n, e.number)
+	}
+	switch e.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func (e inExpr) Match(r Record) bool {
+	v := whereValue(r, e.field, e.weekday)
+	for _, value := range e.values {
+		if v == value {
+			return !e.negate
+		}
+	}
+
+	return e.negate
+}
+
+func (e regexExpr) Match(r Record) bool {
+	return e.re.MatchString(r.Field(e.field)) != e.negate
+}
+
+func (e orExpr) String() string  { return fmt.Sprintf("(%v OR %v)", e.left, e.right) }
+func (e andExpr) String() string { return fmt.Sprintf("(%v AND %v)", e.left, e.right) }
+func (e notExpr) String() string { return fmt.Sprintf("(NOT %v)", e.expr) }
+
+func (e compareExpr) String() string {
+	return fmt.Sprintf("%s %s %q", e.field, e.op, e.value)
+}
+
+func (e inExpr) String() string {
+	values := make([]string, len(e.values))
+	for i, v := range e.values {
+		values[i] = strconv.Quote(v)
+	}
+	op := "IN"
+	if e.negate {
+		op = "NOT IN"
+	}
+
+	return fmt.Sprintf("%s %s (%s)", e.field, op, strings.Join(values, ", "))
+}
+
+func (e regexExpr) String() string {
+	op := "~"
+	if e.negate {
+		op = "!~"
+	}
+
+	return fmt.Sprintf("%s %s %q", e.field, op, e.re.String())
+}
+
+func (e orExpr) fields(fn func(string))      { e.left.fields(fn); e.right.fields(fn) }
+func (e andExpr) fields(fn func(string))     { e.left.fields(fn); e.right.fields(fn) }
+func (e notExpr) fields(fn func(string))     { e.expr.fields(fn) }
+func (e compareExpr) fields(fn func(string)) { fn(e.field) }
+func (e inExpr) fields(fn func(string))      { fn(e.field) }
+func (e regexExpr) fields(fn func(string))   { fn(e.field) }
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWherePrecedence(t *testing.T) {
+	cases := []struct {
+		name  string
+		where string
+		want  string
+	}{
+		{"AND before OR", "postcode = 1 OR postcode = 2 AND recipe = a",
+			`(postcode = "1" OR (postcode = "2" AND recipe = "a"))`},
+		{"NOT before AND", "NOT postcode = 1 AND recipe = a", `((NOT postcode = "1") AND recipe = "a")`},
+		{"Parentheses", "(postcode = 1 OR postcode = 2) AND recipe = a",
+			`((postcode = "1" OR postcode = "2") AND recipe = "a")`},
+		{"Left associative", "postcode = 1 OR postcode = 2 OR postcode = 3",
+			`((postcode = "1" OR postcode = "2") OR postcode = "3")`},
+		{"Symbols and lower case", "!postcode == 1 || recipe = a && recipe != b",
+			`((NOT postcode = "1") OR (recipe = "a" AND recipe != "b"))`},
+		{"Double NOT", "not not postcode = 1", `(NOT (NOT postcode = "1"))`},
+		{"Operators", `postcode starts with 101 and weekday in (Sat, "Sunday") and recipe !~ 'Chicken' and ` +
+			`from_hour >= 10 and recipe not in (a) and recipe ends with s and recipe contains Pork`,
+			`((((((postcode STARTS WITH "101" AND weekday IN ("sat", "sun")) AND recipe !~ "Chicken") AND ` +
+				`from_hour >= "10") AND recipe NOT IN ("a")) AND recipe ENDS WITH "s") AND recipe CONTAINS "Pork")`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, err := ParseWhere(c.where)
+			if err != nil {
+				t.Fatalf("%s, want: %v, got: %v", c.name, nil, err)
+			}
+
+			if got := e.String(); c.want != got {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestParseWhereErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		where  string
+		offset int
+		reason string
+	}{
+		{"Missing operator", "postcode", 8, `unexpected end of the condition, expected an operator after postcode, ` +
+			`e.g. = or IN`},
+		{"Missing value", "postcode = ", 11, "unexpected end of the condition, expected a value after postcode"},
+		{"Keyword as field", "AND postcode = 1", 0, `unexpected "AND", expected a field, e.g. postcode`},
+		{"Missing AND", "postcode = 1 recipe = a", 13, `unexpected "recipe", expected AND or OR`},
+		{"Unclosed parenthesis", "(postcode = 1", 13,
+			"unexpected end of the condition, expected a closing parenthesis"},
+		{"Unterminated string", "recipe = 'Pork", 9, "unterminated string"},
+		{"Unexpected character", "recipe = a; drop", 10, `unexpected character ';'`},
+		{"Invalid regex", "recipe ~ '('", 9,
+			"invalid regular expression: error parsing regexp: missing closing ): `(`"},
+		{"Unknown weekday", "weekday = Caturday", 10, `unknown weekday "Caturday", e.g. Saturday or Sat`},
+		{"STARTS without WITH", "postcode starts 101", 16, `unexpected "101", expected WITH after starts`},
+		{"NOT without IN", "postcode not (1)", 13, `unexpected "(", expected IN after NOT`},
+		{"IN without list", "postcode in 1", 12, `unexpected "1", expected a list of values, e.g. (Sat, Sun)`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			want := &FilterError{"where", c.where, c.offset, c.reason}
+			e, got := ParseWhere(c.where)
+
+			if e != nil || !reflect.DeepEqual(want, got) {
+				t.Errorf("%s, want: %v, got: %v", c.name, want, got)
+			}
+		})
+	}
+}
+
+func TestWhereMatch(t *testing.T) {
+	r := Record{Postcode: "10120", Recipe: "Cherry Balsamic Pork Chops", Delivery: "Saturday 9AM - 2PM",
+		DeliveryDate: "2026-10-03", Attributes: map[string]string{"box_size": "4"}}
+	r.parseDelivery()
+
+	cases := []struct {
+		where string
+		want  bool
+	}{
+		{"postcode = 10120", true},
+		{"postcode != 10120", false},
+		{"postcode starts with 101", true},
+		{"postcode ends with 21", false},
+		{"recipe contains Pork", true},
+		{"recipe ~ '^cherry'", false},
+		{"recipe ~ '(?i)^cherry'", true},
+		{"recipe !~ 'Chicken'", true},
+		{"weekday = sat", true},
+		{"weekday in (Sat, Sun)", true},
+		{"weekday not in (SATURDAY)", false},
+		{"weekday starts with Sat", true},
+		{"from_hour >= 9 AND to_hour < 15", true},
+		{"to_hour > 9", true},
+		{"box_size > 10", false},
+		{"box_size > '10'", true},
+		{"delivery_date <= 2026-10-03", true},
+		{"missing = ''", true},
+		{"postcode = 1 OR postcode = 10120 AND recipe contains Chicken", false},
+		{"(postcode = 1 OR postcode = 10120) AND NOT recipe contains Chicken", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.where, func(t *testing.T) {
+			e, err := ParseWhere(c.where)
+			if err != nil {
+				t.Fatalf("%s, want: %v, got: %v", c.where, nil, err)
+			}
+
+			if got := e.Match(r); c.want != got {
+				t.Errorf("%s, want: %v, got: %v", c.where, c.want, got)
+			}
+		})
+	}
+}
+
+func TestCalculateWhere(t *testing.T) {
+	filter := regularFilter
+	filter.Where = "weekday in (Sat, Sun) AND recipe !~ 'Chicken'"
+	got := NewSummaryCalculator(filter)
+	Parse(sampleFile, &got, false)
+
+	mc := mockCalculator{}
+	Parse(sampleFile, &mc, false)
+	want := NewSummaryCalculator(regularFilter)
+	for _, r := range mc.results {
+		if (r.window.Weekday == "Saturday" || r.window.Weekday == "Sunday") && !strings.Contains(r.Recipe, "Chicken") {
+			want.Calculate(r)
+		}
+	}
+
+	if !reflect.DeepEqual(want.Aggregate(), got.Aggregate()) {
+		t.Errorf("Where, want: %v, got: %v", want.Aggregate(), got.Aggregate())
+	}
+}
+
+func TestNormalizeWhere(t *testing.T) {
+	regions := NewPostcodeNormalizer(0, map[string]string{"10120": `North "A"`})
+	cases := []struct {
+		name       string
+		normalizer *PostcodeNormalizer
+		where      string
+		want       string
+	}{
+		{"Equal", NewPostcodeNormalizer(3, nil), "postcode = 010120 AND recipe = 010120",
+			`postcode = "101" AND recipe = 010120`},
+		{"In and quoted", NewPostcodeNormalizer(3, nil), `postcode NOT IN ('10120', " 10224") OR postcode != 101`,
+			`postcode NOT IN ("101", "102") OR postcode != 101`},
+		{"Region", regions, "NOT postcode == 10120", `NOT postcode == "North \"A\""`},
+		{"Other operators", NewPostcodeNormalizer(3, nil), "postcode starts with 01012 OR postcode ~ '^0'",
+			"postcode starts with 01012 OR postcode ~ '^0'"},
+		{"Invalid", NewPostcodeNormalizer(3, nil), "postcode = 010120 AND", "postcode = 010120 AND"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.normalizer.NormalizeWhere(c.where); got != c.want {
+				t.Errorf("%s, want: %v, got: %v", c.name, c.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeWhereMatch(t *testing.T) {
+	normalizer := NewPostcodeNormalizer(3, nil)
+	e, err := ParseWhere(normalizer.NormalizeWhere("postcode = 010120"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := (Record{Postcode: normalizer.Normalize("10120")}); !e.Match(r) {
+		t.Errorf("Match %v, want: %v, got: %v", r, true, false)
+	}
+}